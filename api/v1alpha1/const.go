@@ -7,4 +7,18 @@ const (
 	// FIXME: domain for labels
 	DatamoverSessionSelectorLabel = "datamover/service_label"
 	DatamoverSessionLabel         = "datamover/session"
+	// Label set on client pods, value is UID of the session the client connects to
+	DatamoverClientLabel = "datamover/client"
+	// Sessions with this label set to "true" are not deleted by session garbage collection
+	DatamoverSessionPinLabel = "datamover/pinned"
+	// Label set on client leases of shared sessions, value is the session name
+	DatamoverLeaseLabel = "datamover/lease"
+	// Finalizer keeping shared sessions from deletion while they have active leases
+	DatamoverSessionLeaseFinalizer = "datamover/leases"
+	// Finalizer releasing the session lease held by the operation when it's deleted
+	DatamoverOperationLeaseFinalizer = "datamover/session-lease"
+	// Label set on sessions created by session.EnsureSession, value is the spec hash
+	DatamoverSessionHashLabel = "datamover/spec-hash"
+	// Label set on client pods created for an operation, value is the operation name
+	DatamoverOperationLabel = "datamover/operation"
 )
@@ -0,0 +1,444 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	DatamoverOperationKind = "DatamoverOperation"
+)
+
+// OperationType is the operation run by the client pod
+// +kubebuilder:validation:Enum=fs_backup;fs_restore;stream_backup;stream_restore;multi_volume_backup;multi_volume_restore;block_backup;block_restore;snapshot_backup;browse;multi_stream_backup;multi_stream_restore
+type OperationType string
+
+const (
+	OperationTypeFsBackup      OperationType = "fs_backup"
+	OperationTypeFsRestore     OperationType = "fs_restore"
+	OperationTypeStreamBackup  OperationType = "stream_backup"
+	OperationTypeStreamRestore OperationType = "stream_restore"
+
+	OperationTypeMultiVolumeBackup  OperationType = "multi_volume_backup"
+	OperationTypeMultiVolumeRestore OperationType = "multi_volume_restore"
+	OperationTypeBlockBackup        OperationType = "block_backup"
+	OperationTypeBlockRestore       OperationType = "block_restore"
+	OperationTypeSnapshotBackup     OperationType = "snapshot_backup"
+	// Long-running operation serving the backup over the browse API
+	OperationTypeBrowse OperationType = "browse"
+
+	OperationTypeMultiStreamBackup  OperationType = "multi_stream_backup"
+	OperationTypeMultiStreamRestore OperationType = "multi_stream_restore"
+)
+
+// DatamoverOperationSpec defines a client operation running against a session
+// The spec is immutable, create a new operation to run it again.
+type DatamoverOperationSpec struct {
+	// Session in the same namespace to connect to
+	SessionRef corev1.LocalObjectReference `json:"sessionRef"`
+	Type       OperationType               `json:"type"`
+	// Client image, should support the session implementation
+	Image string `json:"image"`
+
+	// Parameters of the operation, only the one matching type should be set
+	FsBackup      *FsBackupParams      `json:"fsBackup,omitempty"`
+	FsRestore     *FsRestoreParams     `json:"fsRestore,omitempty"`
+	StreamBackup  *StreamBackupParams  `json:"streamBackup,omitempty"`
+	StreamRestore *StreamRestoreParams `json:"streamRestore,omitempty"`
+
+	MultiVolumeBackup  *MultiVolumeBackupParams  `json:"multiVolumeBackup,omitempty"`
+	MultiVolumeRestore *MultiVolumeRestoreParams `json:"multiVolumeRestore,omitempty"`
+	BlockBackup        *BlockBackupParams        `json:"blockBackup,omitempty"`
+	BlockRestore       *BlockRestoreParams       `json:"blockRestore,omitempty"`
+	SnapshotBackup     *SnapshotBackupParams     `json:"snapshotBackup,omitempty"`
+	Browse             *BrowseParams             `json:"browse,omitempty"`
+	MultiStreamBackup  *MultiStreamBackupParams  `json:"multiStreamBackup,omitempty"`
+	MultiStreamRestore *MultiStreamRestoreParams `json:"multiStreamRestore,omitempty"`
+
+	// Secret with client credentials mounted to /etc/client-secret
+	// Service account token projection is used if not set
+	ClientSecretName string `json:"clientSecretName,omitempty"`
+	// Configmap with client configuration mounted to /etc/config
+	ConfigMap *string `json:"configMap,omitempty"`
+	// Secrets mounted to /etc/secrets/<name> dirs
+	Secrets map[string]string `json:"secrets,omitempty"`
+	// Env variables to pass to the client container
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Extra configurations to pass to client pod
+	PodOptions PodOptions `json:"podOptions,omitempty"`
+
+	// Interval in seconds to report progress of the running operation to status.progress
+	// Progress is not reported if not set
+	// Progress is reported by a native sidecar container, which requires Kubernetes 1.29+,
+	// operation fails if the cluster does not support it
+	// +kubebuilder:validation:Minimum=0
+	ProgressIntervalSeconds int32 `json:"progressIntervalSeconds,omitempty"`
+	// Image of the sidecar reporting progress, the image should provide sh, cat and sleep
+	// Defaults to busybox:1.36.1
+	ProgressImage string `json:"progressImage,omitempty"`
+
+	// Number of retries after transient failures, defaults to 3
+	// Failures with exit code 22 (invalid configuration) are not retried
+	// +kubebuilder:validation:Minimum=0
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// FsBackupParams backs up file system directory at Path from PVC
+type FsBackupParams struct {
+	PVC           string          `json:"pvc"`
+	Path          string          `json:"path,omitempty"`
+	Tag           string          `json:"tag,omitempty"`
+	ReadOnlyMount bool            `json:"readOnlyMount,omitempty"`
+	Metadata      *BackupMetadata `json:"metadata,omitempty"`
+}
+
+// BackupMetadata controls incremental behaviour of the backup and describes the created snapshot
+type BackupMetadata struct {
+	// Snapshot the backup should be incremental to
+	// Backup fails with invalid configuration if it's not a snapshot of the same source
+	// +kubebuilder:validation:Pattern=`^[^\s]*$`
+	ParentSnapshotID string `json:"parentSnapshotID,omitempty"`
+	// Identity of the backed up data, e.g. <namespace>/<pvc>
+	// Backups of the same source are incremental to each other
+	// Defaults to the implementation specific identity, e.g. the mount path
+	// +kubebuilder:validation:Pattern=`^[^\s]*$`
+	Source string `json:"source,omitempty"`
+	// Retention hint for repository maintenance, e.g. daily or weekly
+	// +kubebuilder:validation:Pattern=`^[^\s]*$`
+	RetentionHint string `json:"retentionHint,omitempty"`
+	// Key/value metadata to query backups by
+	// Keys and values cannot contain whitespace, ':' or '='
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// FsRestoreParams restores file system directory to Path in PVC
+// +kubebuilder:validation:XValidation:rule="has(self.pvc) || has(self.pvcTemplate)",message="pvc or pvcTemplate is required"
+type FsRestoreParams struct {
+	// PVC to restore to. If pvcTemplate is set, a new PVC is created with this name,
+	// e.g. to bind it to the original claim name, defaults to <operation name>-restore
+	PVC      string `json:"pvc,omitempty"`
+	Path     string `json:"path,omitempty"`
+	BackupID string `json:"backupID"`
+	// Template to provision a new PVC to restore to
+	PVCTemplate *PVCTemplate `json:"pvcTemplate,omitempty"`
+
+	// Paths in the snapshot to restore, the whole snapshot is restored if empty
+	// Paths are relative and cannot contain whitespace
+	Include []string `json:"include,omitempty"`
+	// Paths in the snapshot to skip
+	Exclude []string `json:"exclude,omitempty"`
+	// What to do with files existing in the PVC, defaults to Overwrite
+	OverwritePolicy OverwritePolicy `json:"overwritePolicy,omitempty"`
+	// Write files with zero blocks as sparse files
+	SparseRestore bool `json:"sparseRestore,omitempty"`
+}
+
+// OverwritePolicy controls restore of files existing in the target
+// +kubebuilder:validation:Enum=Overwrite;Skip;Rename
+type OverwritePolicy string
+
+const (
+	OverwritePolicyOverwrite OverwritePolicy = "Overwrite"
+	OverwritePolicySkip      OverwritePolicy = "Skip"
+	// Restored files are written with .restored suffix if the file exists, or .restored.<n> if that exists too
+	OverwritePolicyRename OverwritePolicy = "Rename"
+)
+
+// PVCTemplate describes a new PVC provisioned for restore
+// +kubebuilder:validation:XValidation:rule="has(self.size) || has(self.backupOperationName)",message="size or backupOperationName is required"
+type PVCTemplate struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Default storage class is used if not set
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// Defaults to ReadWriteOnce
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// Size of the PVC
+	Size *resource.Quantity `json:"size,omitempty"`
+	// DatamoverOperation which created the backup
+	// If set, PVC is sized from the backup size with 10% headroom if it's larger than size
+	// Restore waits for the backup operation to finish and fails if it failed
+	BackupOperationName string `json:"backupOperationName,omitempty"`
+}
+
+// StreamBackupParams backs up data written by StreamGenerator container
+type StreamBackupParams struct {
+	StreamGenerator corev1.Container `json:"streamGenerator"`
+	// Identifier of a stream data in a backup (filename)
+	BackupObjectName string `json:"backupObjectName"`
+	Tag              string `json:"tag,omitempty"`
+	// Image to use for init container creating the stream file
+	InitImage string          `json:"initImage,omitempty"`
+	Metadata  *BackupMetadata `json:"metadata,omitempty"`
+	// Compression algorithm of the implementation, e.g. zstd for kopia
+	// Implementation default is used if not set
+	// +kubebuilder:validation:Pattern=`^[a-z0-9-]+$`
+	Compression string `json:"compression,omitempty"`
+	// Client-side encryption of the stream data
+	Encryption *StreamEncryption `json:"encryption,omitempty"`
+}
+
+// StreamEncryption configures client-side envelope encryption of the stream.
+// Stream is encrypted with a random data key, which is stored with the backup
+// encrypted with the key from the secret. Encrypted data is authenticated with HMAC,
+// restore buffers it in the client pod and verifies it before writing it to the stream.
+type StreamEncryption struct {
+	SecretName string `json:"secretName"`
+	// Key of the secret with the encryption key, defaults to "key"
+	Key string `json:"key,omitempty"`
+}
+
+// StreamRestoreParams restores data read by StreamIngestor container
+type StreamRestoreParams struct {
+	StreamIngestor corev1.Container `json:"streamIngestor"`
+	// Identifier of a stream data in a backup (filename)
+	BackupObjectName string `json:"backupObjectName"`
+	BackupID         string `json:"backupID"`
+	// Image to use for init container creating the stream file
+	InitImage string `json:"initImage,omitempty"`
+	// Encryption the stream was backed up with
+	Encryption *StreamEncryption `json:"encryption,omitempty"`
+	// Checksum reported in the backup result. If set, restored data is verified
+	// before it's written to the stream, which buffers it in the client pod.
+	// +kubebuilder:validation:Pattern=`^[^\s]*$`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// MultiStreamBackupParams backs up data written by stream generators to several named streams into one backup
+// Each stream is a named pipe /tmp/stream_file/<name> created before the generators start
+// Each stream is backed up to its own snapshot, result has backupID grouping them and per-stream snapshot IDs.
+// Snapshots of the streams are deleted if any of the streams fails.
+type MultiStreamBackupParams struct {
+	// Stream names, used as names of the streams in the backup
+	// Names should be valid DNS subdomain names, e.g. db1.sql
+	// +kubebuilder:validation:MinItems=1
+	Streams []string `json:"streams"`
+	// Containers writing to the streams, one container can write to several streams
+	// Streams are read concurrently, so they can be written in any order
+	// +kubebuilder:validation:MinItems=1
+	StreamGenerators []corev1.Container `json:"streamGenerators"`
+	Tag              string             `json:"tag,omitempty"`
+	// Image to use for init container creating the stream files
+	InitImage string `json:"initImage,omitempty"`
+}
+
+// MultiStreamRestoreParams restores streams of a multi-stream backup read by stream ingestors
+type MultiStreamRestoreParams struct {
+	// Streams of the backup to restore to /tmp/stream_file/<name>
+	// +kubebuilder:validation:MinItems=1
+	Streams []string `json:"streams"`
+	// Containers reading the streams, one container can read several streams
+	// +kubebuilder:validation:MinItems=1
+	StreamIngestors []corev1.Container `json:"streamIngestors"`
+	// backupID from the result of the multi-stream backup
+	BackupID string `json:"backupID"`
+	// Image to use for init container creating the stream files
+	InitImage string `json:"initImage,omitempty"`
+}
+
+// MultiVolumeBackupParams backs up multiple PVCs into one snapshot
+type MultiVolumeBackupParams struct {
+	// Map of volume names to PVCs, volume names are used as directories in the snapshot
+	// and should be valid volume names
+	// +kubebuilder:validation:MinProperties=1
+	Volumes       map[string]string `json:"volumes"`
+	Tag           string            `json:"tag,omitempty"`
+	ReadOnlyMount bool              `json:"readOnlyMount,omitempty"`
+}
+
+// MultiVolumeRestoreParams restores volumes of a multi-volume backup
+type MultiVolumeRestoreParams struct {
+	// Map of volume names in the backup to PVCs to restore them to
+	// Volumes missing in the map are not restored
+	// +kubebuilder:validation:MinProperties=1
+	Volumes  map[string]string `json:"volumes"`
+	BackupID string            `json:"backupID"`
+}
+
+// BlockBackupParams backs up raw block volume PVC with volumeMode: Block
+type BlockBackupParams struct {
+	PVC string `json:"pvc"`
+	Tag string `json:"tag,omitempty"`
+}
+
+// BlockRestoreParams restores raw block volume backup to PVC with volumeMode: Block
+type BlockRestoreParams struct {
+	PVC      string `json:"pvc"`
+	BackupID string `json:"backupID"`
+	// Skip writing zero blocks
+	// Only safe if the volume reads as zeros, e.g. new thin provisioned volume
+	SparseRestore bool `json:"sparseRestore,omitempty"`
+}
+
+// SnapshotBackupParams backs up file system directory at Path from a VolumeSnapshot of PVC
+// Snapshot and temporary PVC created from it are deleted after the operation finishes
+type SnapshotBackupParams struct {
+	PVC  string `json:"pvc"`
+	Path string `json:"path,omitempty"`
+	Tag  string `json:"tag,omitempty"`
+	// VolumeSnapshotClass to create the snapshot with, default class is used if not set
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+	// StorageClass of the temporary PVC, defaults to the storage class of PVC
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// Source defaults to <namespace>/<pvc> of the snapshotted PVC
+	Metadata *BackupMetadata `json:"metadata,omitempty"`
+}
+
+// BrowseParams serves the backup over the browse HTTP API, see pkg/browse
+// The API is exposed by a service created for the operation until the operation is deleted.
+// Ingress to the browse pod is restricted by a network policy created for the operation.
+type BrowseParams struct {
+	BackupID string `json:"backupID"`
+	// Port of the browse API, defaults to 8080
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+	// Token required by the browse API in the Authorization: Bearer header
+	Auth BrowseAuth `json:"auth"`
+	// Peers allowed to connect to the browse API
+	// If empty, only pods in the operation namespace can connect
+	From []networkingv1.NetworkPolicyPeer `json:"from,omitempty"`
+}
+
+// BrowseAuth references the secret with the browse API bearer token
+type BrowseAuth struct {
+	SecretName string `json:"secretName"`
+	// Key of the secret with the token, defaults to "token"
+	Key string `json:"key,omitempty"`
+}
+
+// OperationPhase is the field users would check to know the state of DatamoverOperation
+type OperationPhase string
+
+const (
+	// Operation is waiting for the session to be ready
+	OperationPhasePending OperationPhase = "Pending"
+	// Client pod is created
+	OperationPhaseRunning   OperationPhase = "Running"
+	OperationPhaseSucceeded OperationPhase = "Succeeded"
+	OperationPhaseFailed    OperationPhase = "Failed"
+)
+
+// DatamoverOperationStatus defines the observed state of DatamoverOperation
+type DatamoverOperationStatus struct {
+	Phase OperationPhase `json:"phase,omitempty"`
+	// Name of the client pod of the current attempt
+	PodName string `json:"podName,omitempty"`
+	// Number of attempts, counting created client pods and failed volume snapshots
+	Attempts       int32        `json:"attempts,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Last progress reported by the running client
+	Progress *OperationProgress `json:"progress,omitempty"`
+	// PVC the data is restored to, set for fs_restore operations
+	PVCName string `json:"pvcName,omitempty"`
+	// Service exposing the browse API, set for browse operations
+	ServiceName string `json:"serviceName,omitempty"`
+	// Lease held on a shared session until the operation is finished
+	LeaseName string `json:"leaseName,omitempty"`
+	// Result of the succeeded operation
+	Result *OperationResult `json:"result,omitempty"`
+	// Reason of the last failure
+	Message string `json:"message,omitempty"`
+}
+
+// OperationProgress is periodically reported by the running client
+type OperationProgress struct {
+	// Bytes read and hashed by the client
+	BytesHashed int64 `json:"bytesHashed,omitempty"`
+	// Bytes uploaded to the repository
+	BytesUploaded int64 `json:"bytesUploaded,omitempty"`
+	// Number of processed files
+	FilesProcessed int64 `json:"filesProcessed,omitempty"`
+	// Estimated total bytes, zero if unknown
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+	// Estimated time remaining
+	ETA *metav1.Duration `json:"eta,omitempty"`
+}
+
+// OperationResult is reported by the client as JSON in the termination message of the main container
+type OperationResult struct {
+	// ID of the created snapshot, set for backup operations creating one snapshot
+	SnapshotID string `json:"snapshotID,omitempty"`
+	// ID of the multi-stream backup, snapshots of its streams are tagged with it
+	// Used as backupID of the multi-stream restore
+	BackupID string `json:"backupID,omitempty"`
+	// Snapshots of the streams of the multi-stream backup
+	Streams []StreamSnapshot `json:"streams,omitempty"`
+	// Size of the data in bytes
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// Number of files and directories
+	Files       int64 `json:"files,omitempty"`
+	Directories int64 `json:"directories,omitempty"`
+	// Time it took to run the operation
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Checksum of the backed up data as <algorithm>:<hex>, set for stream backups
+	Checksum string `json:"checksum,omitempty"`
+	// Errors reported by the client, e.g. files which could not be read
+	Errors []string `json:"errors,omitempty"`
+}
+
+// StreamSnapshot is the snapshot of one stream of a multi-stream backup
+type StreamSnapshot struct {
+	Name       string `json:"name"`
+	SnapshotID string `json:"snapshotID"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Session",type=string,JSONPath=`.spec.sessionRef.name`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// DatamoverOperation is the Schema for the datamoveroperations API
+type DatamoverOperation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Spec is immutable"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'fs_backup' || has(self.fsBackup)",message="fsBackup is required for fs_backup operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'fs_restore' || has(self.fsRestore)",message="fsRestore is required for fs_restore operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'stream_backup' || has(self.streamBackup)",message="streamBackup is required for stream_backup operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'stream_restore' || has(self.streamRestore)",message="streamRestore is required for stream_restore operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'multi_volume_backup' || has(self.multiVolumeBackup)",message="multiVolumeBackup is required for multi_volume_backup operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'multi_volume_restore' || has(self.multiVolumeRestore)",message="multiVolumeRestore is required for multi_volume_restore operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'block_backup' || has(self.blockBackup)",message="blockBackup is required for block_backup operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'block_restore' || has(self.blockRestore)",message="blockRestore is required for block_restore operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'snapshot_backup' || has(self.snapshotBackup)",message="snapshotBackup is required for snapshot_backup operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'browse' || has(self.browse)",message="browse is required for browse operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'multi_stream_backup' || has(self.multiStreamBackup)",message="multiStreamBackup is required for multi_stream_backup operation"
+	// +kubebuilder:validation:XValidation:rule="self.type != 'multi_stream_restore' || has(self.multiStreamRestore)",message="multiStreamRestore is required for multi_stream_restore operation"
+	Spec   DatamoverOperationSpec   `json:"spec"`
+	Status DatamoverOperationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// DatamoverOperationList contains a list of DatamoverOperation
+type DatamoverOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatamoverOperation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DatamoverOperation{}, &DatamoverOperationList{})
+}
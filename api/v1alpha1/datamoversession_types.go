@@ -27,33 +27,59 @@ const (
 	DatamoverSessionKind = "DatamoverSession"
 )
 
-// TODO: make the whole thing immutable
-
 // DatamoverSessionSpec defines the desired state of DatamoverSession
+// Most of the fields are immutable. Fields which can be changed are:
+// suspend, clientSecretRef and lifecycle.networkPolicy (except enabled)
 type DatamoverSessionSpec struct {
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	Implementation string `json:"implementation"`
-
 	// Configmap in the same namespace referencing implementation specific configuration
 	// This configmap will be mointed to /etc/config dir
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	Configuration *corev1.ConfigMapVolumeSource `json:"config,omitempty"`
 	// A list of secrets to extend implementation specific configuration
 	// These secrets will be mounted to /etc/secrets/<secret-name> dirs
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	ConfigurationSecrets map[string]corev1.SecretVolumeSource `json:"secrets,omitempty"`
 	// ClientSecretRef contains client credentials information
 	// This secret will be mounted to /etc/client_credentials dir
+	// Changing this field will restart the session pod to mount the new secret.
 	ClientSecretRef *corev1.SecretVolumeSource `json:"clientSecretRef,omitempty"`
 	// Implementation specific env variables to pass to the session pod
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	Env map[string]string `json:"env,omitempty"`
-
 	//TODO: dynamic configmap separate from the main config??
-
+	// +kubebuilder:validation:XValidation:rule="has(self.servicePorts) == has(oldSelf.servicePorts) && has(self.service) == has(oldSelf.service) && has(self.podOptions) == has(oldSelf.podOptions) && has(self.startupProbe) == has(oldSelf.startupProbe) && has(self.livenessProbe) == has(oldSelf.livenessProbe)",message="Immutable fields cannot be added or removed"
+	// +kubebuilder:validation:XValidation:rule="(has(self.networkPolicy) && has(self.networkPolicy.enabled) && self.networkPolicy.enabled) == (has(oldSelf.networkPolicy) && has(oldSelf.networkPolicy.enabled) && oldSelf.networkPolicy.enabled)",message="networkPolicy.enabled is immutable"
 	LifecycleConfig *LifecycleConfig `json:"lifecycle,omitempty"`
+	// Suspend deletes the session pod while keeping the service and the session status.
+	// Unsetting it creates a new pod, which goes through readiness again.
+	// This field can be changed after the session is created.
+	Suspend bool `json:"suspend,omitempty"`
+	// Sharing allows multiple clients to use the session by acquiring leases.
+	// Pod and service of a shared session are deleted by the controller once it has
+	// no active leases for longer than the idle timeout, and recreated when a client
+	// acquires a lease. Shared session is not deleted while it has active leases.
+	Sharing *SharingConfig `json:"sharing,omitempty"`
+}
+
+type SharingConfig struct {
+	// How long the session is kept running without active leases.
+	// Also covers the time between the session creation and the first lease.
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=0
+	IdleTimeoutSeconds int32 `json:"idleTimeoutSeconds,omitempty"`
 }
 
 type LifecycleConfig struct {
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	Image string `json:"image"`
 	// Ports to expose via service, service will not be created if empty
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	ServicePorts []corev1.ServicePort `json:"servicePorts,omitempty"`
+	// Service controls how the service ports are exposed
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	Service *ServiceConfig `json:"service,omitempty"`
 
 	// NetworkPolicy controls whether network policy should be created
 	NetworkPolicy NetworkPolicyConfig `json:"networkPolicy,omitempty"`
@@ -61,20 +87,70 @@ type LifecycleConfig struct {
 	// TODO: configurable sidecar readiness timeouts?
 
 	// Extra configurations to pass to session pod
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	PodOptions PodOptions `json:"podOptions,omitempty"`
 
 	// Startup probe to control datamover session lifecycle
 	// More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	StartupProbe *corev1.Probe `json:"startupProbe,omitempty"`
 	// Liveness probe to control datamover session lifecycle
 	// More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
 }
 
+// ServiceType controls how session service is exposed
+// +kubebuilder:validation:Enum=ClusterIP;Headless;NodePort;LoadBalancer
+type ServiceType string
+
+const (
+	ServiceTypeClusterIP    ServiceType = "ClusterIP"
+	ServiceTypeHeadless     ServiceType = "Headless"
+	ServiceTypeNodePort     ServiceType = "NodePort"
+	ServiceTypeLoadBalancer ServiceType = "LoadBalancer"
+)
+
+type ServiceConfig struct {
+	// Type of the service, defaults to ClusterIP
+	// Headless service resolves service DNS name to the session pod IP
+	Type ServiceType `json:"type,omitempty"`
+	// Annotations to add to the service, e.g. to configure load balancer
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// IP families of the service, see service spec ipFamilies
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+	// IP family policy of the service, see service spec ipFamilyPolicy
+	IPFamilyPolicy *corev1.IPFamilyPolicy `json:"ipFamilyPolicy,omitempty"`
+	// Cluster domain used for service DNS name, defaults to cluster.local
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+}
+
+// NetworkPolicyConfig fields other than Enabled can be changed after the session is created.
+// +kubebuilder:validation:XValidation:rule="(has(self.enabled) && self.enabled) || !((has(self.allowClients) && self.allowClients) || has(self.egress))",message="networkPolicy should be enabled to use allowClients or egress"
+// +kubebuilder:validation:XValidation:rule="!has(self.clientNamespaceSelector) || (has(self.allowClients) && self.allowClients)",message="clientNamespaceSelector requires allowClients"
 type NetworkPolicyConfig struct {
 	Enabled bool `json:"enabled,omitempty"`
 
+	// Ingress peers allowed to connect to the session pod
 	From []networkingv1.NetworkPolicyPeer `json:"from,omitempty"`
+	// Allow ingress from client pods created for this session.
+	// Client pods are selected by datamover/client label set by the client library.
+	AllowClients bool `json:"allowClients,omitempty"`
+	// Namespaces to allow client pods from when AllowClients is set.
+	// Only client pods in the session namespace are allowed if not set.
+	ClientNamespaceSelector *metav1.LabelSelector `json:"clientNamespaceSelector,omitempty"`
+	// Egress restricts connections from the session pod, e.g. to the storage endpoint.
+	// All egress is allowed if not set.
+	Egress *EgressPolicyConfig `json:"egress,omitempty"`
+}
+
+type EgressPolicyConfig struct {
+	// Peers the session pod can connect to
+	To []networkingv1.NetworkPolicyPeer `json:"to,omitempty"`
+	// Ports the session pod can connect to
+	Ports []networkingv1.NetworkPolicyPort `json:"ports,omitempty"`
+	// DNS (port 53) is allowed by default to resolve storage endpoints, set to disable that
+	DenyDNS bool `json:"denyDNS,omitempty"`
 }
 
 type PodOptions struct {
@@ -119,6 +195,17 @@ type PodOverride strategicpatch.JSONMap
 type DatamoverSessionStatus struct {
 	SessionInfo SessionInfo              `json:"sessionInfo,omitempty"`
 	Progress    DatamoverSessionProgress `json:"progress,omitempty"`
+	// Time the session progress changed to a failed value, unset for sessions which did not fail
+	FailedTime *metav1.Time `json:"failedTime,omitempty"`
+	// Client leases of a shared session
+	Leases *LeaseStatus `json:"leases,omitempty"`
+}
+
+type LeaseStatus struct {
+	// Number of active client leases
+	Active int32 `json:"active"`
+	// Time since the session has no active leases, unset while leases are active
+	IdleSince *metav1.Time `json:"idleSince,omitempty"`
 }
 
 // SessionInfo contains information to generate endpoint URL to connect to
@@ -129,6 +216,31 @@ type SessionInfo struct {
 	// taking it from the file metadata
 	SessionData string `json:"data,omitempty"`
 	PodErrors   string `json:"podErrors,omitempty"`
+	// Endpoints clients can use to connect to the session service
+	Endpoints []SessionEndpoint `json:"endpoints,omitempty"`
+}
+
+// EndpointType describes how the endpoint can be reached
+type EndpointType string
+
+const (
+	// Service DNS name, reachable in the cluster
+	EndpointTypeDNS EndpointType = "DNS"
+	// Service cluster IP, reachable in the cluster
+	EndpointTypeClusterIP EndpointType = "ClusterIP"
+	// Node port, reachable on any node address
+	EndpointTypeNodePort EndpointType = "NodePort"
+	// Load balancer ingress, reachable outside of the cluster
+	EndpointTypeLoadBalancer EndpointType = "LoadBalancer"
+)
+
+type SessionEndpoint struct {
+	// Name of the service port
+	PortName string       `json:"portName"`
+	Type     EndpointType `json:"type"`
+	// Host to connect to. Empty for NodePort endpoints
+	Host string `json:"host,omitempty"`
+	Port int32  `json:"port"`
 }
 
 // DatamoverSessionProgress is the field users would check to know the state of DatamoverSession
@@ -138,12 +250,22 @@ type DatamoverSessionProgress string
 const (
 	ProgressNone             DatamoverSessionProgress = ""
 	ProgressValidationFailed DatamoverSessionProgress = "ValidationFailed"
+	// Session is waiting for concurrency limits to allow creating resources
+	ProgressQueued DatamoverSessionProgress = "Queued"
+	// Session pod was deleted because session is suspended
+	ProgressSuspended DatamoverSessionProgress = "Suspended"
+	// Session pod is being recreated to apply spec changes
+	ProgressRestarting       DatamoverSessionProgress = "Restarting"
 	ProgressResourcesCreated DatamoverSessionProgress = "ResourcesCreated"
 	ProgressReadinessFailure DatamoverSessionProgress = "ReadinessFailure"
 	ProgressReady            DatamoverSessionProgress = "Ready"
 	ProgressSessionFailure   DatamoverSessionProgress = "SessionFailure"
+	// Shared session pod and service were deleted after the idle timeout.
+	// Session is resumed when a client acquires a lease.
+	ProgressIdle DatamoverSessionProgress = "Idle"
 )
 
+// +genclient
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // DatamoverSession is the Schema for the datamoversessions API
@@ -151,7 +273,7 @@ type DatamoverSession struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +kubebuilder:validation:XValidation:rule="has(self.config) == has(oldSelf.config) && has(self.secrets) == has(oldSelf.secrets) && has(self.env) == has(oldSelf.env) && has(self.lifecycle) == has(oldSelf.lifecycle)",message="Immutable fields cannot be added or removed"
 	Spec   DatamoverSessionSpec   `json:"spec,omitempty"`
 	Status DatamoverSessionStatus `json:"status,omitempty"`
 }
@@ -0,0 +1,2 @@
+// +groupName=dm.cr.kanister.io
+package v1alpha1
@@ -23,16 +23,300 @@ package v1alpha1
 import (
 	"k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupMetadata) DeepCopyInto(out *BackupMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupMetadata.
+func (in *BackupMetadata) DeepCopy() *BackupMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockBackupParams) DeepCopyInto(out *BlockBackupParams) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockBackupParams.
+func (in *BlockBackupParams) DeepCopy() *BlockBackupParams {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockBackupParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockRestoreParams) DeepCopyInto(out *BlockRestoreParams) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockRestoreParams.
+func (in *BlockRestoreParams) DeepCopy() *BlockRestoreParams {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockRestoreParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrowseAuth) DeepCopyInto(out *BrowseAuth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrowseAuth.
+func (in *BrowseAuth) DeepCopy() *BrowseAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(BrowseAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrowseParams) DeepCopyInto(out *BrowseParams) {
+	*out = *in
+	out.Auth = in.Auth
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrowseParams.
+func (in *BrowseParams) DeepCopy() *BrowseParams {
+	if in == nil {
+		return nil
+	}
+	out := new(BrowseParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatamoverOperation) DeepCopyInto(out *DatamoverOperation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatamoverOperation.
+func (in *DatamoverOperation) DeepCopy() *DatamoverOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(DatamoverOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatamoverOperation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatamoverOperationList) DeepCopyInto(out *DatamoverOperationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DatamoverOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatamoverOperationList.
+func (in *DatamoverOperationList) DeepCopy() *DatamoverOperationList {
+	if in == nil {
+		return nil
+	}
+	out := new(DatamoverOperationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DatamoverOperationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatamoverOperationSpec) DeepCopyInto(out *DatamoverOperationSpec) {
+	*out = *in
+	out.SessionRef = in.SessionRef
+	if in.FsBackup != nil {
+		in, out := &in.FsBackup, &out.FsBackup
+		*out = new(FsBackupParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FsRestore != nil {
+		in, out := &in.FsRestore, &out.FsRestore
+		*out = new(FsRestoreParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StreamBackup != nil {
+		in, out := &in.StreamBackup, &out.StreamBackup
+		*out = new(StreamBackupParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StreamRestore != nil {
+		in, out := &in.StreamRestore, &out.StreamRestore
+		*out = new(StreamRestoreParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MultiVolumeBackup != nil {
+		in, out := &in.MultiVolumeBackup, &out.MultiVolumeBackup
+		*out = new(MultiVolumeBackupParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MultiVolumeRestore != nil {
+		in, out := &in.MultiVolumeRestore, &out.MultiVolumeRestore
+		*out = new(MultiVolumeRestoreParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlockBackup != nil {
+		in, out := &in.BlockBackup, &out.BlockBackup
+		*out = new(BlockBackupParams)
+		**out = **in
+	}
+	if in.BlockRestore != nil {
+		in, out := &in.BlockRestore, &out.BlockRestore
+		*out = new(BlockRestoreParams)
+		**out = **in
+	}
+	if in.SnapshotBackup != nil {
+		in, out := &in.SnapshotBackup, &out.SnapshotBackup
+		*out = new(SnapshotBackupParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Browse != nil {
+		in, out := &in.Browse, &out.Browse
+		*out = new(BrowseParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MultiStreamBackup != nil {
+		in, out := &in.MultiStreamBackup, &out.MultiStreamBackup
+		*out = new(MultiStreamBackupParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MultiStreamRestore != nil {
+		in, out := &in.MultiStreamRestore, &out.MultiStreamRestore
+		*out = new(MultiStreamRestoreParams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(string)
+		**out = **in
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.PodOptions.DeepCopyInto(&out.PodOptions)
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatamoverOperationSpec.
+func (in *DatamoverOperationSpec) DeepCopy() *DatamoverOperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatamoverOperationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatamoverOperationStatus) DeepCopyInto(out *DatamoverOperationStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(OperationProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Result != nil {
+		in, out := &in.Result, &out.Result
+		*out = new(OperationResult)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatamoverOperationStatus.
+func (in *DatamoverOperationStatus) DeepCopy() *DatamoverOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatamoverOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatamoverSession) DeepCopyInto(out *DatamoverSession) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatamoverSession.
@@ -117,6 +401,11 @@ func (in *DatamoverSessionSpec) DeepCopyInto(out *DatamoverSessionSpec) {
 		*out = new(LifecycleConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Sharing != nil {
+		in, out := &in.Sharing, &out.Sharing
+		*out = new(SharingConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatamoverSessionSpec.
@@ -132,7 +421,16 @@ func (in *DatamoverSessionSpec) DeepCopy() *DatamoverSessionSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatamoverSessionStatus) DeepCopyInto(out *DatamoverSessionStatus) {
 	*out = *in
-	out.SessionInfo = in.SessionInfo
+	in.SessionInfo.DeepCopyInto(&out.SessionInfo)
+	if in.FailedTime != nil {
+		in, out := &in.FailedTime, &out.FailedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Leases != nil {
+		in, out := &in.Leases, &out.Leases
+		*out = new(LeaseStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatamoverSessionStatus.
@@ -145,6 +443,104 @@ func (in *DatamoverSessionStatus) DeepCopy() *DatamoverSessionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressPolicyConfig) DeepCopyInto(out *EgressPolicyConfig) {
+	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]networkingv1.NetworkPolicyPort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressPolicyConfig.
+func (in *EgressPolicyConfig) DeepCopy() *EgressPolicyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressPolicyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FsBackupParams) DeepCopyInto(out *FsBackupParams) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(BackupMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FsBackupParams.
+func (in *FsBackupParams) DeepCopy() *FsBackupParams {
+	if in == nil {
+		return nil
+	}
+	out := new(FsBackupParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FsRestoreParams) DeepCopyInto(out *FsRestoreParams) {
+	*out = *in
+	if in.PVCTemplate != nil {
+		in, out := &in.PVCTemplate, &out.PVCTemplate
+		*out = new(PVCTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FsRestoreParams.
+func (in *FsRestoreParams) DeepCopy() *FsRestoreParams {
+	if in == nil {
+		return nil
+	}
+	out := new(FsRestoreParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaseStatus) DeepCopyInto(out *LeaseStatus) {
+	*out = *in
+	if in.IdleSince != nil {
+		in, out := &in.IdleSince, &out.IdleSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseStatus.
+func (in *LeaseStatus) DeepCopy() *LeaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LifecycleConfig) DeepCopyInto(out *LifecycleConfig) {
 	*out = *in
@@ -155,6 +551,11 @@ func (in *LifecycleConfig) DeepCopyInto(out *LifecycleConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	in.NetworkPolicy.DeepCopyInto(&out.NetworkPolicy)
 	in.PodOptions.DeepCopyInto(&out.PodOptions)
 	if in.StartupProbe != nil {
@@ -179,6 +580,104 @@ func (in *LifecycleConfig) DeepCopy() *LifecycleConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiStreamBackupParams) DeepCopyInto(out *MultiStreamBackupParams) {
+	*out = *in
+	if in.Streams != nil {
+		in, out := &in.Streams, &out.Streams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StreamGenerators != nil {
+		in, out := &in.StreamGenerators, &out.StreamGenerators
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiStreamBackupParams.
+func (in *MultiStreamBackupParams) DeepCopy() *MultiStreamBackupParams {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiStreamBackupParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiStreamRestoreParams) DeepCopyInto(out *MultiStreamRestoreParams) {
+	*out = *in
+	if in.Streams != nil {
+		in, out := &in.Streams, &out.Streams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StreamIngestors != nil {
+		in, out := &in.StreamIngestors, &out.StreamIngestors
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiStreamRestoreParams.
+func (in *MultiStreamRestoreParams) DeepCopy() *MultiStreamRestoreParams {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiStreamRestoreParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiVolumeBackupParams) DeepCopyInto(out *MultiVolumeBackupParams) {
+	*out = *in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiVolumeBackupParams.
+func (in *MultiVolumeBackupParams) DeepCopy() *MultiVolumeBackupParams {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiVolumeBackupParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiVolumeRestoreParams) DeepCopyInto(out *MultiVolumeRestoreParams) {
+	*out = *in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiVolumeRestoreParams.
+func (in *MultiVolumeRestoreParams) DeepCopy() *MultiVolumeRestoreParams {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiVolumeRestoreParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkPolicyConfig) DeepCopyInto(out *NetworkPolicyConfig) {
 	*out = *in
@@ -189,6 +688,16 @@ func (in *NetworkPolicyConfig) DeepCopyInto(out *NetworkPolicyConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ClientNamespaceSelector != nil {
+		in, out := &in.ClientNamespaceSelector, &out.ClientNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = new(EgressPolicyConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicyConfig.
@@ -201,6 +710,100 @@ func (in *NetworkPolicyConfig) DeepCopy() *NetworkPolicyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationProgress) DeepCopyInto(out *OperationProgress) {
+	*out = *in
+	if in.ETA != nil {
+		in, out := &in.ETA, &out.ETA
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationProgress.
+func (in *OperationProgress) DeepCopy() *OperationProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationResult) DeepCopyInto(out *OperationResult) {
+	*out = *in
+	if in.Streams != nil {
+		in, out := &in.Streams, &out.Streams
+		*out = make([]StreamSnapshot, len(*in))
+		copy(*out, *in)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationResult.
+func (in *OperationResult) DeepCopy() *OperationResult {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCTemplate) DeepCopyInto(out *PVCTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCTemplate.
+func (in *PVCTemplate) DeepCopy() *PVCTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodOptions) DeepCopyInto(out *PodOptions) {
 	*out = *in
@@ -271,9 +874,61 @@ func (in PodOverride) DeepCopy() PodOverride {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceConfig) DeepCopyInto(out *ServiceConfig) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]v1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(v1.IPFamilyPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceConfig.
+func (in *ServiceConfig) DeepCopy() *ServiceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionEndpoint) DeepCopyInto(out *SessionEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionEndpoint.
+func (in *SessionEndpoint) DeepCopy() *SessionEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SessionInfo) DeepCopyInto(out *SessionInfo) {
 	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]SessionEndpoint, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionInfo.
@@ -285,3 +940,125 @@ func (in *SessionInfo) DeepCopy() *SessionInfo {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharingConfig) DeepCopyInto(out *SharingConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharingConfig.
+func (in *SharingConfig) DeepCopy() *SharingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SharingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotBackupParams) DeepCopyInto(out *SnapshotBackupParams) {
+	*out = *in
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(BackupMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotBackupParams.
+func (in *SnapshotBackupParams) DeepCopy() *SnapshotBackupParams {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotBackupParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamBackupParams) DeepCopyInto(out *StreamBackupParams) {
+	*out = *in
+	in.StreamGenerator.DeepCopyInto(&out.StreamGenerator)
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(BackupMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(StreamEncryption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamBackupParams.
+func (in *StreamBackupParams) DeepCopy() *StreamBackupParams {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamBackupParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamEncryption) DeepCopyInto(out *StreamEncryption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamEncryption.
+func (in *StreamEncryption) DeepCopy() *StreamEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamRestoreParams) DeepCopyInto(out *StreamRestoreParams) {
+	*out = *in
+	in.StreamIngestor.DeepCopyInto(&out.StreamIngestor)
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(StreamEncryption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamRestoreParams.
+func (in *StreamRestoreParams) DeepCopy() *StreamRestoreParams {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamRestoreParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamSnapshot) DeepCopyInto(out *StreamSnapshot) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamSnapshot.
+func (in *StreamSnapshot) DeepCopy() *StreamSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
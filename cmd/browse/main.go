@@ -0,0 +1,100 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Browse server serves a kopia snapshot over the browse API, see pkg/browse.
+// Kopia should be connected to the repository before starting the server.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/kanisterio/datamover/pkg/browse"
+	dmclient "github.com/kanisterio/datamover/pkg/client"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+	var snapshotID string
+	var address string
+	var tokenFile string
+	flag.StringVar(&snapshotID, "snapshot", "", "ID of the snapshot to serve.")
+	flag.StringVar(&address, "address", ":8080", "The address the browse API binds to.")
+	flag.StringVar(&tokenFile, "token-file", "", "File with the token required by the browse API.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	log := ctrl.Log.WithName("browse")
+
+	if snapshotID == "" {
+		log.Info("Snapshot ID is required")
+		os.Exit(int(dmclient.InvalidConfigExitCode))
+	}
+
+	token, err := os.ReadFile(tokenFile)
+	if err != nil || strings.TrimSpace(string(token)) == "" {
+		log.Info("Token file is required", "file", tokenFile)
+		os.Exit(int(dmclient.InvalidConfigExitCode))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	snapshot, err := browse.NewKopiaSnapshot(ctx, snapshotID, nil)
+	if errors.Is(err, browse.ErrSnapshotNotFound) {
+		log.Error(err, "Unable to browse snapshot")
+		os.Exit(int(dmclient.InvalidConfigExitCode))
+	}
+	if err != nil {
+		log.Error(err, "Unable to browse snapshot")
+		os.Exit(1)
+	}
+
+	server := &http.Server{
+		Addr:              address,
+		Handler:           browse.NewHandler(snapshot, strings.TrimSpace(string(token))),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Finish running requests on termination
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("Serving snapshot", "snapshot", snapshotID, "address", address)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error(err, "Browse server failed")
+		os.Exit(1)
+	}
+	<-stopped
+}
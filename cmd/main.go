@@ -20,6 +20,7 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -32,6 +33,7 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	api "github.com/kanisterio/datamover/api/v1alpha1"
 	"github.com/kanisterio/datamover/pkg/controller"
 	// +kubebuilder:scaffold:imports
 )
@@ -46,6 +48,8 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var limits controller.ConcurrencyLimits
+	var failedSessionTTL time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -57,6 +61,14 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.IntVar(&limits.MaxSessions, "max-sessions", 0,
+		"Max number of sessions running at the same time. Sessions over the limit are queued. 0 means no limit.")
+	flag.IntVar(&limits.MaxSessionsPerNamespace, "max-sessions-per-namespace", 0,
+		"Max number of sessions running at the same time in a single namespace. 0 means no limit.")
+	flag.IntVar(&limits.MaxSessionsPerImplementation, "max-sessions-per-implementation", 0,
+		"Max number of sessions running at the same time for a single implementation. 0 means no limit.")
+	flag.DurationVar(&failedSessionTTL, "failed-session-ttl", 0,
+		"Delete failed sessions older than this. Sessions labelled "+api.DatamoverSessionPinLabel+"=true are kept. 0 disables deletion.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -110,7 +122,7 @@ func main() {
 
 	restConfig := ctrl.GetConfigOrDie()
 
-	mgr, err := controller.MakeControllerManager(restConfig, ctrl.Options{
+	mgr, err := controller.MakeControllerManagerWithConfig(restConfig, ctrl.Options{
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
@@ -127,6 +139,9 @@ func main() {
 		// if you are doing or is intended to do any operation such as perform cleanups
 		// after the manager stops then its usage might be unsafe.
 		// LeaderElectionReleaseOnCancel: true,
+	}, controller.Config{
+		Limits:           limits,
+		FailedSessionTTL: failedSessionTTL,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -0,0 +1,56 @@
+#!/usr/bin/env bash
+
+# Generates typed clientset, listers and informers for api/v1alpha1 into pkg/generated.
+# client-gen derives the group package from the directory name and treats "api"
+# as the core group, so the API package is linked as dm/v1alpha1 while generating.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+MODULE=github.com/kanisterio/datamover
+BIN=${LOCALBIN:-${ROOT}/bin}
+HEADER=${ROOT}/hack/boilerplate.go.txt
+OUTPUT_PKG=${MODULE}/pkg/generated
+
+TMP_DIR=$(mktemp -d)
+LINK_DIR=${ROOT}/.codegen
+cleanup() {
+  rm -rf "${TMP_DIR}" "${LINK_DIR}"
+}
+trap cleanup EXIT
+
+mkdir -p "${LINK_DIR}/dm"
+ln -s ../../api/v1alpha1 "${LINK_DIR}/dm/v1alpha1"
+INPUT_PKG=${MODULE}/.codegen/dm/v1alpha1
+
+cd "${ROOT}"
+
+"${BIN}/client-gen" \
+  --go-header-file "${HEADER}" \
+  --input-base "${MODULE}/.codegen" \
+  --input dm/v1alpha1 \
+  --clientset-name versioned \
+  --output-package "${OUTPUT_PKG}/clientset" \
+  --output-base "${TMP_DIR}"
+
+"${BIN}/lister-gen" \
+  --go-header-file "${HEADER}" \
+  --input-dirs "${INPUT_PKG}" \
+  --output-package "${OUTPUT_PKG}/listers" \
+  --output-base "${TMP_DIR}"
+
+"${BIN}/informer-gen" \
+  --go-header-file "${HEADER}" \
+  --input-dirs "${INPUT_PKG}" \
+  --versioned-clientset-package "${OUTPUT_PKG}/clientset/versioned" \
+  --listers-package "${OUTPUT_PKG}/listers" \
+  --output-package "${OUTPUT_PKG}/informers" \
+  --output-base "${TMP_DIR}"
+
+rm -rf "${ROOT}/pkg/generated"
+cp -r "${TMP_DIR}/${OUTPUT_PKG}" "${ROOT}/pkg/generated"
+grep -rl "${INPUT_PKG}" "${ROOT}/pkg/generated" | xargs sed -i.bak "s|${INPUT_PKG}|${MODULE}/api/v1alpha1|g"
+find "${ROOT}/pkg/generated" -name '*.bak' -delete
+gofmt -w "${ROOT}/pkg/generated"
@@ -33,10 +33,185 @@ protocols_supported ${PROTOCOLS:?"PROTOCOLS must be defined"}
 
 echo "Using protocol ${protocol} with PORT=${port}"
 
+## Operation result
+
+## Result is written as JSON to the termination message path
+## and parsed by the datamover client library
+result_file=${RESULT_FILE:-/dev/termination-log}
+start_time=$(date +%s)
+## Describes the current step in the error result
+step="Failed to start operation"
+
+write_result() {
+    local snapshot_id=${1:-}
+    local size=${2:-0}
+    local files=${3:-0}
+    local dirs=${4:-0}
+    local errors=${5:-}
+    local duration=$(( $(date +%s) - start_time ))
+    printf '{"snapshotID":"%s","sizeBytes":%s,"files":%s,"directories":%s,"duration":"%ss","errors":[%s]}\n' \
+        "${snapshot_id}" "${size}" "${files}" "${dirs}" "${duration}" "${errors}" > ${result_file}
+}
+
+## Report the failed step, set as ERR trap
+write_error() {
+    write_result "" 0 0 0 "\"${step}\""
+}
+
+set -o errtrace
+trap write_error ERR
+
+## Read a field from compact kopia JSON output, first match wins
+json_field() {
+    echo "$1" | grep -o "\"$2\":\"\?[^,\"}]*" | head -n1 | cut -d":" -f2 | tr -d '"' || true
+}
+
+## Write result from kopia snapshot create --json output
+write_snapshot_result() {
+    local output=$1
+    local errors=""
+    local error_count=$(json_field "${output}" errorCount)
+    if [[ ${error_count:-0} -gt 0 ]]; then
+        errors="\"Failed to back up ${error_count} entries\""
+    fi
+    write_result \
+        "$(json_field "${output}" id)" \
+        "$(json_field "${output}" totalSize)" \
+        "$(json_field "${output}" fileCount)" \
+        "$(json_field "${output}" dirCount)" \
+        "${errors}"
+}
+
+## Report the failed step and exit with invalid configuration code, which is not retried
+invalid_config_exit_code=22
+fail_invalid_config() {
+    write_error
+    exit ${invalid_config_exit_code}
+}
+
+## Backup options
+
+## Parse tag and --<name>=<value> options following positional backup arguments
+## Sets tags_args, parent_snapshot and source_override
+parse_backup_options() {
+    tags_args=""
+    parent_snapshot=""
+    source_override=""
+    local arg
+    for arg in "$@"; do
+        case $arg in
+            --parent=*)
+                parent_snapshot=${arg#--parent=}
+                ;;
+            --source=*)
+                source_override=${arg#--source=}
+                ;;
+            --retention=*)
+                tags_args="${tags_args} --tags retention:${arg#--retention=}"
+                ;;
+            --label=*)
+                local label=${arg#--label=}
+                tags_args="${tags_args} --tags ${label%%=*}:${label#*=}"
+                ;;
+            *)
+                if [[ $arg ]]; then
+                    tags_args="${tags_args} --tags ${arg}"
+                fi
+                ;;
+        esac
+    done
+}
+
+## Set source_args for the snapshot of the path, should run after connecting to the repository
+## Fails with invalid configuration if the parent is not a snapshot of the source
+set_snapshot_source() {
+    local source=$1
+    source_args=""
+    if [[ $source_override ]]; then
+        source="${username}@${hostname}:/${source_override}"
+        source_args="--override-source=${source}"
+    fi
+    if [[ $parent_snapshot ]]; then
+        step="Failed to list snapshots of ${source}"
+        local snapshots
+        snapshots=$(kopia snapshot list ${source} --json)
+        if ! echo "${snapshots}" | grep -q "\"id\":\"${parent_snapshot}\""; then
+            step="Parent snapshot ${parent_snapshot} is not a snapshot of ${source}"
+            fail_invalid_config
+        fi
+    fi
+}
+
+## Progress
+
+## Progress is written as JSON to the progress file
+## and printed by the progress sidecar, not reported if not set
+progress_file=${PROGRESS_FILE:-}
+
+## Convert kopia size string, e.g. "1.5 GB", to bytes
+to_bytes() {
+    local value=$1
+    local unit=$2
+    local multiplier=1
+    case $unit in
+        KB) multiplier=1000 ;;
+        MB) multiplier=1000000 ;;
+        GB) multiplier=1000000000 ;;
+        TB) multiplier=1000000000000 ;;
+        PB) multiplier=1000000000000000 ;;
+        KiB) multiplier=1024 ;;
+        MiB) multiplier=1048576 ;;
+        GiB) multiplier=1073741824 ;;
+        TiB) multiplier=1099511627776 ;;
+        PiB) multiplier=1125899906842624 ;;
+    esac
+    local whole=${value%.*}
+    local fraction=0
+    if [[ $value == *.* ]]; then
+        ## kopia prints sizes with one decimal digit
+        fraction=${value#*.}
+        fraction=${fraction:0:1}
+    fi
+    echo $(( 10#${whole} * multiplier + 10#${fraction} * multiplier / 10 ))
+}
+
+## Parse kopia snapshot create progress from stdin and write it to the progress file
+## Progress lines are passed through to stderr
+report_progress() {
+    ## Progress must not fail or report the operation result
+    trap - ERR
+    set +o errexit
+    set +o xtrace
+    local size='([0-9.]+) ([KMGTP]?i?B)'
+    local pattern="([0-9]+) hashed \\(${size}\\), ([0-9]+) cached \\(${size}\\), uploaded ${size}"
+    local estimate_pattern="estimated ${size} .* ([0-9hms]+) left"
+    local line
+    while IFS= read -r -d $'\r' line || [[ -n $line ]]; do
+        echo "${line}" >&2
+        if [[ -z ${progress_file} ]] || [[ ! $line =~ $pattern ]]; then
+            continue
+        fi
+        local files=$(( BASH_REMATCH[1] + BASH_REMATCH[4] ))
+        local hashed=$(( $(to_bytes ${BASH_REMATCH[2]} ${BASH_REMATCH[3]}) + $(to_bytes ${BASH_REMATCH[5]} ${BASH_REMATCH[6]}) ))
+        local uploaded=$(to_bytes ${BASH_REMATCH[7]} ${BASH_REMATCH[8]})
+        local total=0
+        local eta=""
+        if [[ $line =~ $estimate_pattern ]]; then
+            total=$(to_bytes ${BASH_REMATCH[1]} ${BASH_REMATCH[2]})
+            eta=",\"eta\":\"${BASH_REMATCH[3]}\""
+        fi
+        ## Write to a temporary file so the sidecar never reads a partial line
+        printf '{"bytesHashed":%s,"bytesUploaded":%s,"filesProcessed":%s,"totalBytes":%s%s}\n' \
+            "${hashed}" "${uploaded}" "${files}" "${total}" "${eta}" > ${progress_file}.tmp
+        mv ${progress_file}.tmp ${progress_file}
+    done
+}
+
 ## Data volume mount
 
 ## TODO: data/data is a bit redundant, change that if we chose to support only one volume
-data_mount=/mnt/data/data
+## Overridden by the sidecar mode to the data volume mount path
+data_mount=${DATA_MOUNT:-/mnt/data/data}
 
 connect_to_repo() {
     ## Read client config
@@ -95,40 +270,357 @@ connect_to_repo() {
         # --parallelism...
 }
 
+## Restore options
+
+## Parse --<name>=<value> options following positional restore arguments
+## Sets restore_includes, restore_excludes, overwrite_policy and restore_args
+parse_restore_options() {
+    restore_includes=()
+    restore_excludes=()
+    overwrite_policy=overwrite
+    restore_args=""
+    local arg
+    for arg in "$@"; do
+        case $arg in
+            --include=*)
+                restore_includes+=("${arg#--include=}")
+                ;;
+            --exclude=*)
+                restore_excludes+=("${arg#--exclude=}")
+                ;;
+            --overwrite=*)
+                overwrite_policy=${arg#--overwrite=}
+                ;;
+            --sparse)
+                restore_args="${restore_args} --write-sparse-files"
+                ;;
+        esac
+    done
+    case $overwrite_policy in
+        overwrite)
+            restore_args="${restore_args} --overwrite-files --overwrite-directories --overwrite-symlinks"
+            ;;
+        skip)
+            restore_args="${restore_args} --skip-existing"
+            ;;
+        rename)
+            ;;
+        *)
+            step="Unsupported overwrite policy ${overwrite_policy}"
+            fail_invalid_config
+            ;;
+    esac
+}
+
+## Restore path of the snapshot to the same path in target, skipping excluded paths
+## Directories containing excluded paths are listed and restored entry by entry
+restore_tree() {
+    local backup_id=$1
+    local path=$2
+    local target=$3
+
+    local exclude
+    local partial=false
+    for exclude in "${restore_excludes[@]}"; do
+        if [[ $path == "${exclude}" || $path == "${exclude}"/* ]]; then
+            return 0
+        fi
+        if [[ -z $path || $exclude == "${path}"/* ]]; then
+            partial=true
+        fi
+    done
+
+    if [[ $partial == false ]]; then
+        restore_path "${backup_id}" "${path}" "${target}"
+        return 0
+    fi
+
+    ## Entry names come from the snapshot, so paths are always quoted
+    local entries entry
+    entries=$(kopia ls "${backup_id}${path:+/${path}}")
+    while IFS= read -r entry; do
+        if [[ $entry ]]; then
+            restore_tree "${backup_id}" "${path:+${path}/}${entry%/}" "${target}"
+        fi
+    done <<< "${entries}"
+}
+
+restore_path() {
+    local source=$1${2:+/$2}
+    local destination=$3${2:+/$2}
+    if [[ $overwrite_policy == rename ]]; then
+        restore_renaming "${source}" "${destination}"
+    else
+        kopia snapshot restore ${restore_args} "${source}" "${destination}"
+    fi
+}
+
+## Restore to a staging directory on the same volume and move restored files to the destination
+## Existing files are kept and restored files are written with .restored suffix
+restore_renaming() {
+    local source=$1
+    local destination=$2
+    local staging=${data_mount}/.datamover-restore
+
+    rm -rf "${staging}"
+    mkdir -p "${staging}"
+    kopia snapshot restore ${restore_args} "${source}" "${staging}/data"
+
+    if [[ -d ${staging}/data ]]; then
+        local file
+        while IFS= read -r file; do
+            mkdir -p "${destination}/${file#./}"
+        done < <(cd "${staging}/data" && find . -type d)
+        while IFS= read -r file; do
+            move_renaming "${staging}/data/${file#./}" "${destination}/${file#./}"
+        done < <(cd "${staging}/data" && find . ! -type d)
+    else
+        move_renaming "${staging}/data" "${destination}"
+    fi
+    rm -rf "${staging}"
+}
+
+## Move the file without overwriting the existing one, existing files get
+## the restored file as <name>.restored, or <name>.restored.<n> if that exists too
+move_renaming() {
+    local from=$1
+    local to=$2
+    mkdir -p "$(dirname "${to}")"
+    if [[ -e ${to} || -L ${to} ]]; then
+        local renamed="${to}.restored"
+        local n=1
+        while [[ -e ${renamed} || -L ${renamed} ]]; do
+            renamed="${to}.restored.${n}"
+            n=$(( n + 1 ))
+        done
+        to=${renamed}
+    fi
+    mv "${from}" "${to}"
+}
+
 # backup
 run_backup() {
     local path_prefix=${1:?"Path prefix required"}
-    local tags=${2:-}
-
-    local tags_arg=""
-    if [[ $tags ]]; then
-        tags_arg="--tags ${tags}"
-    fi
+    ## Optional tag and metadata options
+    parse_backup_options "${@:2}"
 
     ## Connect to repo
+    step="Failed to connect to repository"
     connect_to_repo
 
+    ## Incremental to the previous snapshot of the source
+    set_snapshot_source ${data_mount}/${path_prefix}
+
     ## FIXME: do we start prefix with /???
     ## TODO: do we want to pass config parameters (e.g. log, cache dir etc)
-    ## TODO: parallelism, progress, etc
+    ## TODO: parallelism, etc
     ## FIXME: make json parameter optional (env variable)??
-    kopia snapshot create --json ${data_mount}/${path_prefix} $tags_arg
+    step="Failed to create snapshot"
+    local output
+    output=$(kopia snapshot create --json --progress ${data_mount}/${path_prefix} ${tags_args} ${source_args} 2> >(report_progress))
+    echo "${output}"
 
-    ## TODO: parse output??
+    write_snapshot_result "${output}"
 }
 # restore
 run_restore() {
     local path_prefix=$1
     local backup_id=$2
+    ## Optional include, exclude, overwrite and sparse options
+    parse_restore_options "${@:3}"
 
     ## Connect to repo
+    step="Failed to connect to repository"
     connect_to_repo
 
-
     ## FIXME: do we start prefix with /???
-    ## TODO: sparseRestore
-    kopia snapshot restore ${backup_id} ${data_mount}/${path_prefix}
-    ## TODO: parse output??
+    step="Failed to restore snapshot"
+    local target=${data_mount}/${path_prefix}
+    if [[ ${#restore_includes[@]} -eq 0 ]]; then
+        restore_tree "${backup_id}" "" "${target}"
+    else
+        local include
+        for include in "${restore_includes[@]}"; do
+            restore_tree "${backup_id}" "${include}" "${target}"
+        done
+    fi
+    ## TODO: restore stats
+    write_result
+}
+
+## Multi-volume operations
+
+## Volumes are mounted to /mnt/data/<name>
+volumes_mount=/mnt/data
+
+# multi-volume backup
+## All volumes are backed up into one snapshot with a directory per volume
+run_multi_volume_backup() {
+    ## Comma separated volume names
+    local volume_names=${1:?"Volume names required"}
+    local tags=${2:-}
+
+    local tags_arg=""
+    if [[ $tags ]]; then
+        tags_arg="--tags ${tags}"
+    fi
+
+    step="Volume is not mounted"
+    for name in $(echo ${volume_names} | tr "," " "); do
+        test -d ${volumes_mount}/${name}
+    done
+
+    ## Connect to repo
+    step="Failed to connect to repository"
+    connect_to_repo
+
+    step="Failed to create snapshot"
+    local output
+    output=$(kopia snapshot create --json --progress ${volumes_mount} $tags_arg 2> >(report_progress))
+    echo "${output}"
+
+    write_snapshot_result "${output}"
+}
+# multi-volume restore
+## Restores each volume directory from the snapshot to the volume mounted with the same name
+run_multi_volume_restore() {
+    local backup_id=${1:?"Backup ID required"}
+    ## Comma separated volume names
+    local volume_names=${2:?"Volume names required"}
+
+    ## Connect to repo
+    step="Failed to connect to repository"
+    connect_to_repo
+
+    for name in $(echo ${volume_names} | tr "," " "); do
+        step="Failed to restore volume ${name}"
+        kopia snapshot restore ${backup_id}/${name} ${volumes_mount}/${name}
+    done
+    write_result
+}
+
+## Block volume operations
+
+## Name of the device data in the snapshot
+block_object_name=disk
+
+# block backup
+## Zero blocks are deduplicated by kopia, so unused regions of the device
+## do not take space in the repository
+run_block_backup() {
+    local device=${1:?"Device path required"}
+    local tags=${2:-}
+
+    local tags_arg=""
+    if [[ $tags ]]; then
+        tags_arg="--tags ${tags}"
+    fi
+
+    step="Block device is not attached"
+    test -b ${device}
+
+    ## Connect to repo
+    step="Failed to connect to repository"
+    connect_to_repo
+
+    step="Failed to create snapshot"
+    local output
+    output=$(dd if=${device} bs=1M status=none | kopia snapshot create --json --progress ${tags_arg} --stdin-file ${block_object_name} - 2> >(report_progress))
+    echo "${output}"
+
+    write_snapshot_result "${output}"
+}
+# block restore
+run_block_restore() {
+    local device=${1:?"Device path required"}
+    local backup_id=${2:?"Backup ID required"}
+    ## Skip writing zero blocks, only safe for devices reading as zeros
+    local sparse=${3:-false}
+
+    local conv_arg="fsync"
+    if [[ $sparse == "true" ]]; then
+        conv_arg="fsync,sparse"
+    fi
+
+    step="Block device is not attached"
+    test -b ${device}
+
+    ## Connect to repo
+    step="Failed to connect to repository"
+    connect_to_repo
+
+    step="Failed to restore snapshot"
+    kopia show ${backup_id}/${block_object_name} | dd of=${device} bs=1M iflag=fullblock conv=${conv_arg} status=none
+    write_result
+}
+
+## Sidecar mode
+
+# sidecar
+## Reads commands from the named pipe and runs them one at a time:
+##   backup <id> <path> [tag]
+##   restore <id> <path> <backup id>
+## Result of each command is printed as "result: <id> <result JSON>"
+run_sidecar() {
+    local command_file=${1:?"Command file required"}
+    local mount_path=${2:-${data_mount}}
+
+    step="Failed to create command file"
+    if [[ ! -p ${command_file} ]]; then
+        mkfifo ${command_file}
+    fi
+
+    local command id path arg
+    while true; do
+        ## Pipe is reopened after each writer closes it
+        while read -r command id path arg; do
+            run_sidecar_command "${mount_path}" "${command}" "${id}" "${path}" "${arg}"
+        done < ${command_file}
+    done
+}
+
+run_sidecar_command() {
+    local mount_path=$1
+    local command=$2
+    local id=${3:-}
+    local path=${4:-}
+    local arg=${5:-}
+
+    local command_result=$(mktemp)
+    local op=""
+    case $command in
+        "backup")
+            op=fs_backup
+            ;;
+        "restore")
+            op=fs_restore
+            ;;
+    esac
+    if [[ -z $op || -z $id || -z $path ]]; then
+        echo "{\"errors\":[\"Invalid command: ${command}\"]}" > ${command_result}
+    else
+        ## Run in a separate process so a failed command does not stop the sidecar
+        DATA_MOUNT=${mount_path} RESULT_FILE=${command_result} "$0" ${op} ${path} ${arg} || true
+    fi
+    echo "result: ${id:-unknown} $(cat ${command_result})"
+    rm -f ${command_result}
+}
+
+# browse server
+## Serves the snapshot over the browse API until the pod is terminated
+## Browse server exits with code 22 if the snapshot does not exist
+run_browse_server() {
+    local backup_id=${1:?"Backup ID required"}
+    local browse_port=${2:-8080}
+    local token_file=${3:?"Browse API token file required"}
+
+    ## Connect to repo
+    step="Failed to connect to repository"
+    connect_to_repo
+
+    step="Failed to start browse server"
+    ## Replace the shell so the server receives termination signal
+    exec datamover-browse --snapshot=${backup_id} --address=:${browse_port} --token-file=${token_file}
 }
 
 ## Check command arguments
@@ -142,6 +634,24 @@ case $command in
     "fs_restore")
         run_restore ${@:2}
         ;;
+    "multi_volume_backup")
+        run_multi_volume_backup ${@:2}
+        ;;
+    "multi_volume_restore")
+        run_multi_volume_restore ${@:2}
+        ;;
+    "block_backup")
+        run_block_backup ${@:2}
+        ;;
+    "block_restore")
+        run_block_restore ${@:2}
+        ;;
+    "fs_sidecar")
+        run_sidecar ${@:2}
+        ;;
+    "browse_server")
+        run_browse_server ${@:2}
+        ;;
     *)
         echo "Not supported command ${command}"
         exit 1
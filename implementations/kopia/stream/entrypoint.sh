@@ -33,6 +33,298 @@ protocols_supported ${PROTOCOLS:?"PROTOCOLS must be defined"}
 
 echo "Using protocol ${protocol} with PORT=${port}"
 
+## Operation result
+
+## Result is written as JSON to the termination message path
+## and parsed by the datamover client library
+result_file=${RESULT_FILE:-/dev/termination-log}
+start_time=$(date +%s)
+## Describes the current step in the error result
+step="Failed to start operation"
+## Checksum of the stream data as <algorithm>:<hex>
+result_checksum=""
+## Multi-stream backup ID and JSON list of stream snapshots
+result_backup_id=""
+result_streams=""
+
+write_result() {
+    local snapshot_id=${1:-}
+    local size=${2:-0}
+    local files=${3:-0}
+    local dirs=${4:-0}
+    local errors=${5:-}
+    local duration=$(( $(date +%s) - start_time ))
+    printf '{"snapshotID":"%s","backupID":"%s","streams":[%s],"sizeBytes":%s,"files":%s,"directories":%s,"duration":"%ss","checksum":"%s","errors":[%s]}\n' \
+        "${snapshot_id}" "${result_backup_id}" "${result_streams}" "${size}" "${files}" "${dirs}" "${duration}" "${result_checksum}" "${errors}" > ${result_file}
+}
+
+## Report the failed step, set as ERR trap
+write_error() {
+    write_result "" 0 0 0 "\"${step}\""
+}
+
+set -o errtrace
+trap write_error ERR
+
+## Read a field from compact kopia JSON output, first match wins
+json_field() {
+    echo "$1" | grep -o "\"$2\":\"\?[^,\"}]*" | head -n1 | cut -d":" -f2 | tr -d '"' || true
+}
+
+## Write result from kopia snapshot create --json output
+write_snapshot_result() {
+    local output=$1
+    local errors=""
+    local error_count=$(json_field "${output}" errorCount)
+    if [[ ${error_count:-0} -gt 0 ]]; then
+        errors="\"Failed to back up ${error_count} entries\""
+    fi
+    write_result \
+        "$(json_field "${output}" id)" \
+        "$(json_field "${output}" totalSize)" \
+        "$(json_field "${output}" fileCount)" \
+        "$(json_field "${output}" dirCount)" \
+        "${errors}"
+}
+
+## Report the failed step and exit with invalid configuration code, which is not retried
+invalid_config_exit_code=22
+fail_invalid_config() {
+    write_error
+    exit ${invalid_config_exit_code}
+}
+
+## Backup options
+
+## Parse tag and --<name>=<value> options following positional backup arguments
+## Sets tags_args, parent_snapshot and source_override
+parse_backup_options() {
+    tags_args=""
+    parent_snapshot=""
+    source_override=""
+    compression=""
+    encryption_key_file=""
+    local arg
+    for arg in "$@"; do
+        case $arg in
+            --compression=*)
+                compression=${arg#--compression=}
+                ;;
+            --encryption-key=*)
+                encryption_key_file=${arg#--encryption-key=}
+                ;;
+            --parent=*)
+                parent_snapshot=${arg#--parent=}
+                ;;
+            --source=*)
+                source_override=${arg#--source=}
+                ;;
+            --retention=*)
+                tags_args="${tags_args} --tags retention:${arg#--retention=}"
+                ;;
+            --label=*)
+                local label=${arg#--label=}
+                tags_args="${tags_args} --tags ${label%%=*}:${label#*=}"
+                ;;
+            *)
+                if [[ $arg ]]; then
+                    tags_args="${tags_args} --tags ${arg}"
+                fi
+                ;;
+        esac
+    done
+}
+
+## Set source_args for the snapshot of the path, should run after connecting to the repository
+## Fails with invalid configuration if the parent is not a snapshot of the source
+## Sets snapshot_source to the source of the snapshot
+set_snapshot_source() {
+    local source=$1
+    source_args=""
+    if [[ $source_override ]]; then
+        source="${username}@${hostname}:/${source_override}"
+        source_args="--override-source=${source}"
+    fi
+    snapshot_source=${source}
+    if [[ $parent_snapshot ]]; then
+        step="Failed to list snapshots of ${source}"
+        local snapshots
+        snapshots=$(kopia snapshot list ${source} --json)
+        if ! echo "${snapshots}" | grep -q "\"id\":\"${parent_snapshot}\""; then
+            step="Parent snapshot ${parent_snapshot} is not a snapshot of ${source}"
+            fail_invalid_config
+        fi
+    fi
+}
+
+## Stream encryption
+
+## Stream is encrypted with a random data key, the data key is encrypted with the key
+## from the encryption key file and stored in the header line of the backed up stream:
+##   datamover-envelope-v2 <base64 encrypted data key>
+## Header is followed by the encrypted data and HMAC-SHA256 of the encrypted data as hex.
+## Encryption and MAC keys are derived from the data key.
+envelope_header=datamover-envelope-v2
+cipher_args="-aes-256-cbc -pbkdf2"
+## Size of the hex HMAC at the end of the encrypted stream
+mac_size=64
+
+## Print HMAC-SHA256 of stdin with the 32 bytes hex key as hex
+## Calculated with sha256sum, so the key is not passed in command arguments
+hmac_sha256() {
+    local key=$1
+    local ipad="" opad="" i byte hex
+    for (( i = 0; i < 64; i += 2 )); do
+        byte=$(( 16#${key:i:2} ))
+        printf -v hex '\\x%02x' $(( byte ^ 0x36 ))
+        ipad+=${hex}
+        printf -v hex '\\x%02x' $(( byte ^ 0x5c ))
+        opad+=${hex}
+    done
+    ## Key is padded with zeros to the block size of 64 bytes
+    for (( i = 0; i < 32; i++ )); do
+        ipad+='\x36'
+        opad+='\x5c'
+    done
+    local inner
+    inner=$({ printf "${ipad}"; cat; } | sha256sum | cut -d" " -f1)
+    { printf "${opad}"; printf "$(echo "${inner}" | sed 's/../\\x&/g')"; } | sha256sum | cut -d" " -f1
+}
+
+## Derive the encryption or the MAC key from the data key
+derive_key() {
+    local data_key=$1
+    local purpose=$2
+    printf '%s' "datamover-${purpose}" | hmac_sha256 "${data_key}"
+}
+
+require_openssl() {
+    if ! command -v openssl > /dev/null; then
+        step="Stream encryption requires openssl in the client image"
+        fail_invalid_config
+    fi
+}
+
+## Encrypt stdin to stdout if encryption_key_file is set
+## Runs in a pipeline, tracing is disabled to not print the data key to the logs
+encrypt_stream() {
+    set +o xtrace
+    if [[ -z $encryption_key_file ]]; then
+        cat
+        return
+    fi
+    local data_key wrapped_key
+    data_key=$(openssl rand -hex 32)
+    wrapped_key=$(printf '%s' "${data_key}" | openssl enc ${cipher_args} -salt -pass file:${encryption_key_file} -a -A)
+    echo "${envelope_header} ${wrapped_key}"
+
+    ## MAC of the encrypted data is calculated while it's written and appended at the end
+    local mac_pipe=$(mktemp -u)
+    local mac_file=$(mktemp)
+    mkfifo ${mac_pipe}
+    hmac_sha256 "$(derive_key "${data_key}" mac)" < ${mac_pipe} > ${mac_file} &
+    local mac_pid=$!
+    openssl enc ${cipher_args} -salt -pass fd:3 3<<< "$(derive_key "${data_key}" encryption)" | tee ${mac_pipe}
+    wait ${mac_pid}
+    printf '%s' "$(cat ${mac_file})"
+    rm -f ${mac_pipe} ${mac_file}
+}
+
+## Verify the encrypted file and decrypt it to stdout
+## Exits with 2 if the file was modified, nothing is written to stdout in that case
+## Runs in a subshell, tracing is disabled to not print the data key to the logs
+decrypt_file() (
+    set +o xtrace
+    local file=$1
+    local header wrapped_key data_key
+    read -r header wrapped_key < ${file}
+    if [[ $header != "${envelope_header}" ]]; then
+        echo "Backup is not encrypted with envelope encryption" >&2
+        exit 2
+    fi
+    local header_size=$(head -n1 ${file} | wc -c)
+    local data_size=$(( $(stat -c %s ${file}) - header_size - mac_size ))
+    if [[ $data_size -lt 0 ]]; then
+        echo "Encrypted backup is truncated" >&2
+        exit 2
+    fi
+    data_key=$(printf '%s' "${wrapped_key}" | openssl enc -d ${cipher_args} -pass file:${encryption_key_file} -a -A) || exit 2
+
+    local expected_mac actual_mac
+    expected_mac=$(tail -c ${mac_size} ${file})
+    actual_mac=$(tail -c +$(( header_size + 1 )) ${file} | head -c ${data_size} | hmac_sha256 "$(derive_key "${data_key}" mac)")
+    if [[ $actual_mac != "${expected_mac}" ]]; then
+        echo "Encrypted backup failed authentication" >&2
+        exit 2
+    fi
+    tail -c +$(( header_size + 1 )) ${file} | head -c ${data_size} | openssl enc -d ${cipher_args} -pass fd:3 3<<< "$(derive_key "${data_key}" encryption)"
+)
+
+## Progress
+
+## Progress is written as JSON to the progress file
+## and printed by the progress sidecar, not reported if not set
+progress_file=${PROGRESS_FILE:-}
+
+## Convert kopia size string, e.g. "1.5 GB", to bytes
+to_bytes() {
+    local value=$1
+    local unit=$2
+    local multiplier=1
+    case $unit in
+        KB) multiplier=1000 ;;
+        MB) multiplier=1000000 ;;
+        GB) multiplier=1000000000 ;;
+        TB) multiplier=1000000000000 ;;
+        PB) multiplier=1000000000000000 ;;
+        KiB) multiplier=1024 ;;
+        MiB) multiplier=1048576 ;;
+        GiB) multiplier=1073741824 ;;
+        TiB) multiplier=1099511627776 ;;
+        PiB) multiplier=1125899906842624 ;;
+    esac
+    local whole=${value%.*}
+    local fraction=0
+    if [[ $value == *.* ]]; then
+        ## kopia prints sizes with one decimal digit
+        fraction=${value#*.}
+        fraction=${fraction:0:1}
+    fi
+    echo $(( 10#${whole} * multiplier + 10#${fraction} * multiplier / 10 ))
+}
+
+## Parse kopia snapshot create progress from stdin and write it to the progress file
+## Progress lines are passed through to stderr
+report_progress() {
+    ## Progress must not fail or report the operation result
+    trap - ERR
+    set +o errexit
+    set +o xtrace
+    local size='([0-9.]+) ([KMGTP]?i?B)'
+    local pattern="([0-9]+) hashed \\(${size}\\), ([0-9]+) cached \\(${size}\\), uploaded ${size}"
+    local estimate_pattern="estimated ${size} .* ([0-9hms]+) left"
+    local line
+    while IFS= read -r -d $'\r' line || [[ -n $line ]]; do
+        echo "${line}" >&2
+        if [[ -z ${progress_file} ]] || [[ ! $line =~ $pattern ]]; then
+            continue
+        fi
+        local files=$(( BASH_REMATCH[1] + BASH_REMATCH[4] ))
+        local hashed=$(( $(to_bytes ${BASH_REMATCH[2]} ${BASH_REMATCH[3]}) + $(to_bytes ${BASH_REMATCH[5]} ${BASH_REMATCH[6]}) ))
+        local uploaded=$(to_bytes ${BASH_REMATCH[7]} ${BASH_REMATCH[8]})
+        local total=0
+        local eta=""
+        if [[ $line =~ $estimate_pattern ]]; then
+            total=$(to_bytes ${BASH_REMATCH[1]} ${BASH_REMATCH[2]})
+            eta=",\"eta\":\"${BASH_REMATCH[3]}\""
+        fi
+        ## Write to a temporary file so the sidecar never reads a partial line
+        printf '{"bytesHashed":%s,"bytesUploaded":%s,"filesProcessed":%s,"totalBytes":%s%s}\n' \
+            "${hashed}" "${uploaded}" "${files}" "${total}" "${eta}" > ${progress_file}.tmp
+        mv ${progress_file}.tmp ${progress_file}
+    done
+}
+
 ## Read client config
 
 ## FIXME: do we even need that for kopia??
@@ -98,23 +390,60 @@ run_backup() {
     local stream_file=${1:?"Stream file required"}
     ## Filename in the snapshot, 3rd arg
     local backup_file=${2:?"Backup object name required"}
-    ## Optional tags, 4th arg
-    local tags=${3:-}
+    ## Optional tag and metadata options, 4th arg onwards
+    parse_backup_options "${@:3}"
 
-    local tags_arg=""
-    if [[ $tags ]]; then
-        tags_arg="--tags ${tags}"
+    ## Parent of the stream snapshot can only be checked for an explicit source
+    if [[ $parent_snapshot && -z $source_override ]]; then
+        step="Parent snapshot requires source for stream backups"
+        fail_invalid_config
     fi
 
     ## Connect to repo
+    step="Failed to connect to repository"
     connect_to_repo
 
+    ## Compression is set as a policy of the snapshot source,
+    ## so the stream gets its own source if not set
+    if [[ $compression && -z $source_override ]]; then
+        source_override=streams/${backup_file}
+    fi
+
+    ## Incremental to the previous snapshot of the source
+    set_snapshot_source -
+
+    if [[ $compression ]]; then
+        step="Failed to set compression ${compression}"
+        if ! kopia policy set ${snapshot_source} --compression=${compression}; then
+            fail_invalid_config
+        fi
+    fi
+    if [[ $encryption_key_file ]]; then
+        require_openssl
+    fi
+
+    ## Checksum of the stream data is calculated before encryption
+    step="Failed to calculate checksum"
+    local checksum_pipe=$(mktemp -u)
+    local checksum_file=$(mktemp)
+    mkfifo ${checksum_pipe}
+    sha256sum < ${checksum_pipe} | cut -d" " -f1 > ${checksum_file} &
+    local checksum_pid=$!
+
     ## TODO: do we want to pass config parameters (e.g. log, cache dir etc)
     ## TODO: parallelism, progress, etc
     ## FIXME: make json parameter optional (env variable)??
-    cat ${stream_file} | kopia snapshot create --json ${tags_arg} --stdin-file ${backup_file} -
+    step="Failed to create snapshot"
+    local output
+    output=$(cat ${stream_file} | tee ${checksum_pipe} | encrypt_stream | kopia snapshot create --json --progress ${tags_args} ${source_args} --stdin-file ${backup_file} - 2> >(report_progress))
+    echo "${output}"
+
+    step="Failed to calculate checksum"
+    wait ${checksum_pid}
+    result_checksum="sha256:$(cat ${checksum_file})"
+    rm -f ${checksum_pipe} ${checksum_file}
 
-    ## TODO: parse output??
+    write_snapshot_result "${output}"
 }
 # restore
 run_restore() {
@@ -124,12 +453,216 @@ run_restore() {
     local backup_file=${2:?"Backup object name required"}
     ## Backup id, 4th arg
     local backup_id=${3:?"Backup ID required"}
+    ## Optional encryption and checksum options
+    parse_restore_options "${@:4}"
+
+    if [[ $expected_checksum && $expected_checksum != sha256:* ]]; then
+        step="Unsupported checksum ${expected_checksum}"
+        fail_invalid_config
+    fi
+    if [[ $encryption_key_file ]]; then
+        require_openssl
+    fi
+
+    ## Connect to repo
+    step="Failed to connect to repository"
+    connect_to_repo
+
+    step="Failed to restore snapshot"
+    if [[ -z $expected_checksum && -z $encryption_key_file ]]; then
+        kopia show ${backup_id}/${backup_file} > ${stream_file}
+        write_result
+        return
+    fi
+
+    ## Data is only written to the stream after it's verified
+    local buffer=${buffer_dir}/${backup_file}
+    if ! kopia show ${backup_id}/${backup_file} > ${buffer}; then
+        rm -f ${buffer}
+        close_stream_and_fail ${stream_file}
+    fi
+    if [[ $encryption_key_file ]]; then
+        step="Failed to decrypt snapshot"
+        local decrypted=${buffer}.decrypted
+        local decrypt_code=0
+        decrypt_file ${buffer} > ${decrypted} || decrypt_code=$?
+        rm -f ${buffer}
+        if [[ $decrypt_code -eq 2 ]]; then
+            rm -f ${decrypted}
+            step="Encrypted snapshot failed authentication, it was modified or the key is wrong"
+            close_stream_and_fail ${stream_file} ${invalid_config_exit_code}
+        fi
+        if [[ $decrypt_code -ne 0 ]]; then
+            rm -f ${decrypted}
+            close_stream_and_fail ${stream_file}
+        fi
+        mv ${decrypted} ${buffer}
+    fi
+    if [[ $expected_checksum ]]; then
+        step="Failed to calculate checksum"
+        result_checksum="sha256:$(sha256sum ${buffer} | cut -d" " -f1)"
+        if [[ $result_checksum != "${expected_checksum}" ]]; then
+            rm -f ${buffer}
+            step="Checksum mismatch: expected ${expected_checksum}, restored ${result_checksum}"
+            close_stream_and_fail ${stream_file} ${invalid_config_exit_code}
+        fi
+    fi
+    step="Failed to write restored data"
+    cat ${buffer} > ${stream_file}
+    rm -f ${buffer}
+    write_result
+}
+
+## Parse --<name>=<value> options following positional restore arguments
+## Sets encryption_key_file, expected_checksum and buffer_dir
+parse_restore_options() {
+    encryption_key_file=""
+    expected_checksum=""
+    buffer_dir=/tmp
+    local arg
+    for arg in "$@"; do
+        case $arg in
+            --encryption-key=*)
+                encryption_key_file=${arg#--encryption-key=}
+                ;;
+            --checksum=*)
+                expected_checksum=${arg#--checksum=}
+                ;;
+            --buffer-dir=*)
+                buffer_dir=${arg#--buffer-dir=}
+                ;;
+        esac
+    done
+}
+
+## Close the stream without data, so the ingestor does not wait for it, and fail
+close_stream_and_fail() {
+    local stream_file=$1
+    local exit_code=${2:-1}
+    : > ${stream_file}
+    write_error
+    exit ${exit_code}
+}
+
+## Multi-stream operations
+
+## Each stream is backed up as a separate snapshot with stream source,
+## snapshots are tagged with the backup ID and the stream name.
+## Backup ID is not a snapshot ID, it's reported as backupID with snapshot IDs of the streams.
+backup_tag=datamover-backup
+stream_tag=datamover-stream
+
+## Wait for all background processes in pids, fail if any of them failed
+wait_all() {
+    local pid failed=false
+    for pid in "${pids[@]}"; do
+        wait ${pid} || failed=true
+    done
+    if [[ $failed == true ]]; then
+        write_error
+        exit 1
+    fi
+}
+
+# multi-stream backup
+run_multi_stream_backup() {
+    ## Directory with stream files, 2nd arg
+    local stream_dir=${1:?"Stream directory required"}
+    ## Comma separated stream names, 3rd arg
+    local names=${2:?"Stream names required"}
+    ## Optional tag, 4th arg
+    parse_backup_options "${@:3}"
 
     ## Connect to repo
+    step="Failed to connect to repository"
     connect_to_repo
 
-    kopia show ${backup_id}/${backup_file} > ${stream_file}
-    ## TODO: parse output??
+    local backup_id=$(head -c 16 /dev/urandom | od -An -tx1 | tr -d ' \n')
+    local outputs=$(mktemp -d)
+    local name pid failed=false
+    pids=()
+    ## Streams are read concurrently, so generators can write them in any order
+    step="Failed to create snapshot"
+    for name in $(echo ${names} | tr "," " "); do
+        kopia snapshot create --json ${tags_args} \
+            --tags ${backup_tag}:${backup_id} --tags ${stream_tag}:${name} \
+            --override-source=${username}@${hostname}:/streams/${name} \
+            --stdin-file ${name} - < ${stream_dir}/${name} > ${outputs}/${name} &
+        pids+=($!)
+    done
+    for pid in "${pids[@]}"; do
+        wait ${pid} || failed=true
+    done
+
+    local size=0 files=0 output snapshot_id stream_size stream_files
+    local snapshot_ids=()
+    for name in $(echo ${names} | tr "," " "); do
+        output=$(cat ${outputs}/${name})
+        echo "${output}"
+        snapshot_id=$(json_field "${output}" id)
+        if [[ -z $snapshot_id ]]; then
+            failed=true
+            continue
+        fi
+        snapshot_ids+=(${snapshot_id})
+        result_streams="${result_streams:+${result_streams},}{\"name\":\"${name}\",\"snapshotID\":\"${snapshot_id}\"}"
+        stream_size=$(json_field "${output}" totalSize)
+        stream_files=$(json_field "${output}" fileCount)
+        size=$(( size + ${stream_size:-0} ))
+        files=$(( files + ${stream_files:-0} ))
+    done
+    rm -rf ${outputs}
+
+    ## Partial backup is not usable, delete snapshots of the streams which succeeded
+    if [[ $failed == true ]]; then
+        for snapshot_id in "${snapshot_ids[@]}"; do
+            kopia snapshot delete ${snapshot_id} --delete || echo "Failed to delete snapshot ${snapshot_id}"
+        done
+        result_streams=""
+        write_error
+        exit 1
+    fi
+    result_backup_id=${backup_id}
+    write_result "" ${size} ${files}
+}
+
+# multi-stream restore
+run_multi_stream_restore() {
+    ## Directory with stream files, 2nd arg
+    local stream_dir=${1:?"Stream directory required"}
+    ## Backup id, 3rd arg
+    local backup_id=${2:?"Backup ID required"}
+    ## Comma separated stream names, 4th arg
+    local names=${3:?"Stream names required"}
+
+    ## Connect to repo
+    step="Failed to connect to repository"
+    connect_to_repo
+
+    ## Find snapshots before opening the streams, so missing streams fail early
+    local name snapshots snapshot_id
+    local snapshot_ids=()
+    for name in $(echo ${names} | tr "," " "); do
+        step="Failed to list snapshots of stream ${name}"
+        snapshots=$(kopia snapshot list --all --json --tags ${backup_tag}:${backup_id} --tags ${stream_tag}:${name})
+        snapshot_id=$(json_field "$(echo "${snapshots}" | tr -d ' \n')" id)
+        if [[ -z $snapshot_id ]]; then
+            step="Stream ${name} not found in backup ${backup_id}"
+            fail_invalid_config
+        fi
+        snapshot_ids+=(${snapshot_id})
+    done
+
+    step="Failed to restore snapshot"
+    local i=0
+    pids=()
+    for name in $(echo ${names} | tr "," " "); do
+        kopia show ${snapshot_ids[$i]}/${name} > ${stream_dir}/${name} &
+        pids+=($!)
+        i=$(( i + 1 ))
+    done
+    wait_all
+    write_result
 }
 
 ## Check command arguments
@@ -143,6 +676,12 @@ case $command in
     "stream_restore")
         run_restore ${@:2}
         ;;
+    "multi_stream_backup")
+        run_multi_stream_backup ${@:2}
+        ;;
+    "multi_stream_restore")
+        run_multi_stream_restore ${@:2}
+        ;;
     *)
         echo "Not supported command ${command}"
         exit 1
@@ -0,0 +1,287 @@
+package controller
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// Queued sessions are enqueued when an active session releases its slot,
+	// this is a fallback in case the event is missed
+	queuedRecheckInterval = 60
+	// How long admitted session is counted as active before its pod
+	// shows up in the cache
+	admissionGracePeriod = time.Minute
+)
+
+// ConcurrencyLimits controls how many sessions can have running pods at the same time.
+// Zero value for a limit means no limit.
+type ConcurrencyLimits struct {
+	// Max number of active sessions in the cluster
+	MaxSessions int
+	// Max number of active sessions in a single namespace
+	MaxSessionsPerNamespace int
+	// Max number of active sessions of a single implementation
+	MaxSessionsPerImplementation int
+}
+
+func (limits ConcurrencyLimits) enabled() bool {
+	return limits.MaxSessions > 0 || limits.MaxSessionsPerNamespace > 0 || limits.MaxSessionsPerImplementation > 0
+}
+
+// admissions tracks sessions which were admitted, but their pods
+// may not be visible in the cache yet
+type admissions struct {
+	mu       sync.Mutex
+	admitted map[types.UID]time.Time
+}
+
+func (a *admissions) add(uid types.UID) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.admitted == nil {
+		a.admitted = map[types.UID]time.Time{}
+	}
+	a.admitted[uid] = time.Now()
+}
+
+// pending returns admitted sessions which don't have pods yet.
+// Expired admissions and admissions for sessions with pods are removed.
+func (a *admissions) pending(withPods map[types.UID]bool) map[types.UID]bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	result := map[types.UID]bool{}
+	for uid, admittedAt := range a.admitted {
+		if withPods[uid] || time.Since(admittedAt) > admissionGracePeriod {
+			delete(a.admitted, uid)
+			continue
+		}
+		result[uid] = true
+	}
+	return result
+}
+
+type sessionUsage struct {
+	total           int
+	namespaces      map[string]int
+	implementations map[string]int
+}
+
+func newSessionUsage() sessionUsage {
+	return sessionUsage{
+		namespaces:      map[string]int{},
+		implementations: map[string]int{},
+	}
+}
+
+func (usage sessionUsage) clone() sessionUsage {
+	return sessionUsage{
+		total:           usage.total,
+		namespaces:      maps.Clone(usage.namespaces),
+		implementations: maps.Clone(usage.implementations),
+	}
+}
+
+func (usage *sessionUsage) add(dmSession api.DatamoverSession) {
+	usage.total++
+	usage.namespaces[dmSession.Namespace]++
+	usage.implementations[dmSession.Spec.Implementation]++
+}
+
+func (usage sessionUsage) fits(limits ConcurrencyLimits, dmSession api.DatamoverSession) bool {
+	if limits.MaxSessions > 0 && usage.total >= limits.MaxSessions {
+		return false
+	}
+	if limits.MaxSessionsPerNamespace > 0 && usage.namespaces[dmSession.Namespace] >= limits.MaxSessionsPerNamespace {
+		return false
+	}
+	if limits.MaxSessionsPerImplementation > 0 && usage.implementations[dmSession.Spec.Implementation] >= limits.MaxSessionsPerImplementation {
+		return false
+	}
+	return true
+}
+
+type queuedSession struct {
+	session  api.DatamoverSession
+	priority int32
+}
+
+// sortQueue orders sessions by pod priority (higher first), then by creation time
+func sortQueue(queue []queuedSession) {
+	slices.SortStableFunc(queue, func(a, b queuedSession) int {
+		if a.priority != b.priority {
+			if a.priority > b.priority {
+				return -1
+			}
+			return 1
+		}
+		aTime := a.session.CreationTimestamp
+		bTime := b.session.CreationTimestamp
+		if !aTime.Equal(&bTime) {
+			if aTime.Before(&bTime) {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(a.session.Namespace, b.session.Namespace); c != 0 {
+			return c
+		}
+		return strings.Compare(a.session.Name, b.session.Name)
+	})
+}
+
+// canAdmit walks the queue in order and reserves capacity for sessions which fit.
+// Sessions which don't fit are skipped, so sessions limited by a different
+// namespace or implementation are not blocked by them.
+func canAdmit(limits ConcurrencyLimits, activeUsage sessionUsage, queue []queuedSession, uid types.UID) bool {
+	usage := activeUsage.clone()
+	sortQueue(queue)
+	for _, candidate := range queue {
+		if !usage.fits(limits, candidate.session) {
+			continue
+		}
+		if candidate.session.UID == uid {
+			return true
+		}
+		usage.add(candidate.session)
+	}
+	return false
+}
+
+// admit checks whether the session can create its resources without exceeding concurrency limits.
+// Admitted sessions count as active until their pod stops running.
+func (r *DatamoverSessionReconciler) admit(ctx context.Context, dmSession api.DatamoverSession) (bool, error) {
+	if !r.Limits.enabled() {
+		return true, nil
+	}
+
+	sessionList := &api.DatamoverSessionList{}
+	if err := r.List(ctx, sessionList); err != nil {
+		return false, err
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.HasLabels{api.DatamoverSessionLabel}); err != nil {
+		return false, err
+	}
+
+	withPods := map[types.UID]bool{}
+	for _, pod := range podList.Items {
+		controller := metav1.GetControllerOf(&pod)
+		if controller != nil && controller.Kind == api.DatamoverSessionKind && podAlive(pod) {
+			withPods[controller.UID] = true
+		}
+	}
+	pending := r.admissions.pending(withPods)
+
+	usage := newSessionUsage()
+	queue := []queuedSession{}
+	priorities := map[string]int32{}
+	for _, item := range sessionList.Items {
+		if item.UID == dmSession.UID {
+			continue
+		}
+		if withPods[item.UID] || pending[item.UID] {
+			usage.add(item)
+			continue
+		}
+		if item.Status.Progress == api.ProgressQueued && item.DeletionTimestamp == nil {
+			priority, err := r.getPriority(ctx, item, priorities)
+			if err != nil {
+				return false, err
+			}
+			queue = append(queue, queuedSession{session: item, priority: priority})
+		}
+	}
+
+	priority, err := r.getPriority(ctx, dmSession, priorities)
+	if err != nil {
+		return false, err
+	}
+	queue = append(queue, queuedSession{session: dmSession, priority: priority})
+
+	if !canAdmit(r.Limits, usage, queue, dmSession.UID) {
+		log.Log.Info("Session is queued by concurrency limits", "session", dmSession.Name, "namespace", dmSession.Namespace)
+		return false, nil
+	}
+	r.admissions.add(dmSession.UID)
+	return true, nil
+}
+
+func (r *DatamoverSessionReconciler) getPriority(ctx context.Context, dmSession api.DatamoverSession, cache map[string]int32) (int32, error) {
+	if dmSession.Spec.LifecycleConfig == nil {
+		return 0, nil
+	}
+	className := dmSession.Spec.LifecycleConfig.PodOptions.PriorityClassName
+	if className == "" {
+		return 0, nil
+	}
+	if priority, ok := cache[className]; ok {
+		return priority, nil
+	}
+	priorityClass := &schedulingv1.PriorityClass{}
+	err := r.Get(ctx, types.NamespacedName{Name: className}, priorityClass)
+	switch {
+	case apierrors.IsNotFound(err):
+		// Pod creation will report missing priority class, use default priority for ordering
+		cache[className] = 0
+	case err != nil:
+		return 0, err
+	default:
+		cache[className] = priorityClass.Value
+	}
+	return cache[className], nil
+}
+
+// slotReleased filters events which can release a slot of an active session:
+// session or its pod is deleted, or the pod stops running
+var slotReleased = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldPod, ok := e.ObjectOld.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		newPod, ok := e.ObjectNew.(*corev1.Pod)
+		return ok && podAlive(*oldPod) && !podAlive(*newPod)
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return true },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// queuedSessionRequests enqueues all queued sessions, so they are admitted
+// as soon as an active session releases its slot
+func (r *DatamoverSessionReconciler) queuedSessionRequests(ctx context.Context, _ client.Object) []reconcile.Request {
+	if !r.Limits.enabled() {
+		return nil
+	}
+	sessionList := &api.DatamoverSessionList{}
+	if err := r.List(ctx, sessionList); err != nil {
+		log.Log.Error(err, "Unable to list queued sessions")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, item := range sessionList.Items {
+		if item.Status.Progress == api.ProgressQueued && item.DeletionTimestamp == nil {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: item.Name, Namespace: item.Namespace},
+			})
+		}
+	}
+	return requests
+}
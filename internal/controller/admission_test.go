@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func makeQueuedSession(name, namespace, implementation string, created time.Time, priority int32) queuedSession {
+	return queuedSession{
+		session: api.DatamoverSession{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				UID:               types.UID(namespace + "/" + name),
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: api.DatamoverSessionSpec{
+				Implementation: implementation,
+			},
+		},
+		priority: priority,
+	}
+}
+
+func TestConcurrencyLimitsDisabledByDefault(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	matcher.Expect(ConcurrencyLimits{}.enabled()).To(gomega.BeFalse())
+	matcher.Expect(ConcurrencyLimits{MaxSessionsPerNamespace: 1}.enabled()).To(gomega.BeTrue())
+}
+
+func TestSortQueueByPriorityThenCreationTime(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	now := time.Now()
+	queue := []queuedSession{
+		makeQueuedSession("newest", "ns", "kopia", now, 0),
+		makeQueuedSession("oldest", "ns", "kopia", now.Add(-2*time.Minute), 0),
+		makeQueuedSession("high", "ns", "kopia", now, 100),
+		makeQueuedSession("middle", "ns", "kopia", now.Add(-time.Minute), 0),
+	}
+	sortQueue(queue)
+	names := []string{}
+	for _, item := range queue {
+		names = append(names, item.session.Name)
+	}
+	matcher.Expect(names).To(gomega.Equal([]string{"high", "oldest", "middle", "newest"}))
+}
+
+func TestCanAdmitGlobalLimit(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	now := time.Now()
+	limits := ConcurrencyLimits{MaxSessions: 2}
+	first := makeQueuedSession("first", "ns", "kopia", now.Add(-time.Minute), 0)
+	second := makeQueuedSession("second", "ns", "kopia", now, 0)
+
+	usage := newSessionUsage()
+	usage.add(makeQueuedSession("running", "ns", "kopia", now, 0).session)
+
+	queue := []queuedSession{second, first}
+	matcher.Expect(canAdmit(limits, usage, queue, first.session.UID)).To(gomega.BeTrue())
+	matcher.Expect(canAdmit(limits, usage, queue, second.session.UID)).To(gomega.BeFalse())
+}
+
+func TestCanAdmitHigherPriorityFirst(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	now := time.Now()
+	limits := ConcurrencyLimits{MaxSessions: 1}
+	old := makeQueuedSession("old", "ns", "kopia", now.Add(-time.Hour), 0)
+	urgent := makeQueuedSession("urgent", "ns", "kopia", now, 1000)
+
+	queue := []queuedSession{old, urgent}
+	matcher.Expect(canAdmit(limits, newSessionUsage(), queue, urgent.session.UID)).To(gomega.BeTrue())
+	matcher.Expect(canAdmit(limits, newSessionUsage(), queue, old.session.UID)).To(gomega.BeFalse())
+}
+
+// Session blocked by namespace limit should not block sessions in other namespaces
+func TestCanAdmitSkipsSessionsOverNamespaceLimit(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	now := time.Now()
+	limits := ConcurrencyLimits{MaxSessions: 3, MaxSessionsPerNamespace: 1}
+	usage := newSessionUsage()
+	usage.add(makeQueuedSession("running", "busy", "kopia", now, 0).session)
+
+	blocked := makeQueuedSession("blocked", "busy", "kopia", now.Add(-time.Minute), 0)
+	other := makeQueuedSession("other", "free", "kopia", now, 0)
+	queue := []queuedSession{blocked, other}
+	matcher.Expect(canAdmit(limits, usage, queue, other.session.UID)).To(gomega.BeTrue())
+	matcher.Expect(canAdmit(limits, usage, queue, blocked.session.UID)).To(gomega.BeFalse())
+}
+
+func TestCanAdmitPerImplementationLimit(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	now := time.Now()
+	limits := ConcurrencyLimits{MaxSessionsPerImplementation: 1}
+	usage := newSessionUsage()
+	usage.add(makeQueuedSession("running", "ns", "kopia", now, 0).session)
+
+	kopia := makeQueuedSession("kopia", "ns", "kopia", now, 0)
+	noop := makeQueuedSession("noop", "ns", "noop", now, 0)
+	queue := []queuedSession{kopia, noop}
+	matcher.Expect(canAdmit(limits, usage, queue, kopia.session.UID)).To(gomega.BeFalse())
+	matcher.Expect(canAdmit(limits, usage, queue, noop.session.UID)).To(gomega.BeTrue())
+}
+
+func TestAdmissionsPendingExpire(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	tracker := admissions{}
+	tracker.add("with-pod")
+	tracker.add("without-pod")
+	tracker.add("expired")
+	tracker.admitted["expired"] = time.Now().Add(-2 * admissionGracePeriod)
+
+	pending := tracker.pending(map[types.UID]bool{"with-pod": true})
+	matcher.Expect(pending).To(gomega.Equal(map[types.UID]bool{"without-pod": true}))
+	matcher.Expect(tracker.admitted).To(gomega.HaveLen(1))
+}
+
+func TestSlotReleasedWhenPodStops(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	running := &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+	succeeded := &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}}
+	pending := &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending}}
+	matcher.Expect(slotReleased.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: succeeded})).To(gomega.BeTrue())
+	matcher.Expect(slotReleased.Update(event.UpdateEvent{ObjectOld: pending, ObjectNew: running})).To(gomega.BeFalse())
+	matcher.Expect(slotReleased.Delete(event.DeleteEvent{Object: running})).To(gomega.BeTrue())
+	matcher.Expect(slotReleased.Create(event.CreateEvent{Object: running})).To(gomega.BeFalse())
+	dmSession := &api.DatamoverSession{}
+	matcher.Expect(slotReleased.Update(event.UpdateEvent{ObjectOld: dmSession, ObjectNew: dmSession})).To(gomega.BeFalse())
+	matcher.Expect(slotReleased.Delete(event.DeleteEvent{Object: dmSession})).To(gomega.BeTrue())
+}
+
+func TestQueuedSessionRequests(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	scheme := runtime.NewScheme()
+	matcher.Expect(api.AddToScheme(scheme)).To(gomega.Succeed())
+	queued := makeQueuedSession("queued", "ns", "kopia", time.Now(), 0).session
+	queued.Status.Progress = api.ProgressQueued
+	running := makeQueuedSession("running", "ns", "kopia", time.Now(), 0).session
+	running.Status.Progress = api.ProgressReady
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&queued, &running).Build()
+
+	r := &DatamoverSessionReconciler{Client: cli, Limits: ConcurrencyLimits{MaxSessions: 1}}
+	requests := r.queuedSessionRequests(context.Background(), &running)
+	matcher.Expect(requests).To(gomega.Equal([]reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "queued", Namespace: "ns"}},
+	}))
+
+	// Sessions are never queued without limits
+	r.Limits = ConcurrencyLimits{}
+	matcher.Expect(r.queuedSessionRequests(context.Background(), &running)).To(gomega.BeEmpty())
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	dmclient "github.com/kanisterio/datamover/pkg/client"
+)
+
+// createBrowseService creates the service exposing the browse API of the operation pod.
+// Service selects pods by the operation label, so it stays the same between attempts.
+func (r *DatamoverOperationReconciler) createBrowseService(ctx context.Context, dmOperation *api.DatamoverOperation) error {
+	service := makeBrowseService(*dmOperation)
+	if err := ctrl.SetControllerReference(dmOperation, &service, r.Scheme); err != nil {
+		return err
+	}
+	err := r.Create(ctx, &service)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrap(err, "Unable to create browse service")
+	}
+	if err == nil {
+		log.Log.Info("Created browse service", "service", service.Name)
+	}
+	dmOperation.Status.ServiceName = service.Name
+	return nil
+}
+
+func makeBrowseService(dmOperation api.DatamoverOperation) corev1.Service {
+	port := dmOperation.Spec.Browse.Port
+	if port == 0 {
+		port = dmclient.DefaultBrowsePort
+	}
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dmclient.BrowseServiceName(dmOperation),
+			Namespace: dmOperation.Namespace,
+			Labels:    map[string]string{api.DatamoverOperationLabel: dmOperation.Name},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       dmclient.BrowsePortName,
+				Port:       port,
+				TargetPort: intstr.FromString(dmclient.BrowsePortName),
+				Protocol:   corev1.ProtocolTCP,
+			}},
+			Selector: map[string]string{api.DatamoverOperationLabel: dmOperation.Name},
+		},
+	}
+}
+
+// createBrowseNetworkPolicy creates the network policy allowing ingress to the browse API
+// only from the configured peers
+func (r *DatamoverOperationReconciler) createBrowseNetworkPolicy(ctx context.Context, dmOperation *api.DatamoverOperation) error {
+	np := makeBrowseNetworkPolicy(*dmOperation)
+	if err := ctrl.SetControllerReference(dmOperation, &np, r.Scheme); err != nil {
+		return err
+	}
+	err := r.Create(ctx, &np)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrap(err, "Unable to create browse network policy")
+	}
+	if err == nil {
+		log.Log.Info("Created browse network policy", "networkPolicy", np.Name)
+	}
+	return nil
+}
+
+func makeBrowseNetworkPolicy(dmOperation api.DatamoverOperation) networkingv1.NetworkPolicy {
+	port := dmOperation.Spec.Browse.Port
+	if port == 0 {
+		port = dmclient.DefaultBrowsePort
+	}
+	browsePort := intstr.FromInt32(port)
+	tcp := corev1.ProtocolTCP
+	from := dmOperation.Spec.Browse.From
+	if len(from) == 0 {
+		// Without namespace selector only pods from the policy namespace are selected
+		from = []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+	}
+	return networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dmclient.BrowseServiceName(dmOperation),
+			Namespace: dmOperation.Namespace,
+			Labels:    map[string]string{api.DatamoverOperationLabel: dmOperation.Name},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{api.DatamoverOperationLabel: dmOperation.Name},
+			},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From:  from,
+				Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &browsePort}},
+			}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// cleanupBrowseService deletes the service and the network policy once the browse server stopped
+func (r *DatamoverOperationReconciler) cleanupBrowseService(ctx context.Context, dmOperation api.DatamoverOperation) error {
+	meta := metav1.ObjectMeta{Name: dmclient.BrowseServiceName(dmOperation), Namespace: dmOperation.Namespace}
+	if err := r.Delete(ctx, &corev1.Service{ObjectMeta: meta}); client.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, "Unable to delete browse service")
+	}
+	if err := r.Delete(ctx, &networkingv1.NetworkPolicy{ObjectMeta: meta}); client.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, "Unable to delete browse network policy")
+	}
+	return nil
+}
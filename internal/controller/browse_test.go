@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	dmclient "github.com/kanisterio/datamover/pkg/client"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestBrowseOperation(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	dmOperation := makeOperation(3)
+	dmOperation.Spec.Type = api.OperationTypeBrowse
+	dmOperation.Spec.FsBackup = nil
+	dmOperation.Spec.Browse = &api.BrowseParams{BackupID: "k123", Port: 9000, Auth: api.BrowseAuth{SecretName: "browse-token"}}
+	r := makeOperationReconciler(t, dmOperation, makeOperationSession(api.ProgressReady))
+
+	result := reconcileOperation(t, r)
+	matcher.Expect(result.Status.Phase).To(gomega.Equal(api.OperationPhaseRunning))
+	matcher.Expect(result.Status.ServiceName).To(gomega.Equal("backup-browse"))
+
+	service := corev1.Service{}
+	key := types.NamespacedName{Name: "backup-browse", Namespace: "ns"}
+	matcher.Expect(r.Get(ctx, key, &service)).To(gomega.Succeed())
+	matcher.Expect(service.Spec.Selector).To(gomega.Equal(map[string]string{api.DatamoverOperationLabel: "backup"}))
+	matcher.Expect(service.Spec.Ports).To(gomega.ConsistOf(gomega.And(
+		gomega.HaveField("Port", int32(9000)),
+		gomega.HaveField("TargetPort.StrVal", dmclient.BrowsePortName),
+	)))
+
+	np := networkingv1.NetworkPolicy{}
+	matcher.Expect(r.Get(ctx, key, &np)).To(gomega.Succeed())
+	matcher.Expect(np.Spec.PodSelector.MatchLabels).To(gomega.Equal(map[string]string{api.DatamoverOperationLabel: "backup"}))
+	matcher.Expect(np.Spec.Ingress).To(gomega.ConsistOf(gomega.And(
+		gomega.HaveField("From", gomega.ConsistOf(gomega.HaveField("NamespaceSelector", gomega.BeNil()))),
+		gomega.HaveField("Ports", gomega.ConsistOf(gomega.HaveField("Port.IntVal", int32(9000)))),
+	)))
+
+	pod := corev1.Pod{}
+	matcher.Expect(r.Get(ctx, types.NamespacedName{Name: "backup-1", Namespace: "ns"}, &pod)).To(gomega.Succeed())
+	matcher.Expect(pod.Labels).To(gomega.HaveKeyWithValue(api.DatamoverOperationLabel, "backup"))
+	matcher.Expect(pod.Spec.Containers[0].Ports).To(gomega.ConsistOf(gomega.HaveField("ContainerPort", int32(9000))))
+
+	// Stopped server deletes the service
+	finishPod(t, r, "backup-1", corev1.PodSucceeded, 0, "{}")
+	result = reconcileOperation(t, r)
+	matcher.Expect(result.Status.Phase).To(gomega.Equal(api.OperationPhaseSucceeded))
+	reconcileOperation(t, r)
+	matcher.Expect(apierrors.IsNotFound(r.Get(ctx, key, &service))).To(gomega.BeTrue())
+	matcher.Expect(apierrors.IsNotFound(r.Get(ctx, key, &np))).To(gomega.BeTrue())
+}
+
+func TestBrowseNetworkPolicyPeers(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	dmOperation := makeOperation(3)
+	dmOperation.Spec.Type = api.OperationTypeBrowse
+	dmOperation.Spec.FsBackup = nil
+	peer := networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "ui"}},
+	}
+	dmOperation.Spec.Browse = &api.BrowseParams{BackupID: "k123", Auth: api.BrowseAuth{SecretName: "browse-token"}, From: []networkingv1.NetworkPolicyPeer{peer}}
+
+	np := makeBrowseNetworkPolicy(*dmOperation)
+	matcher.Expect(np.Spec.PolicyTypes).To(gomega.ConsistOf(networkingv1.PolicyTypeIngress))
+	matcher.Expect(np.Spec.Ingress).To(gomega.HaveLen(1))
+	matcher.Expect(np.Spec.Ingress[0].From).To(gomega.Equal([]networkingv1.NetworkPolicyPeer{peer}))
+	matcher.Expect(np.Spec.Ingress[0].Ports[0].Port.IntVal).To(gomega.Equal(dmclient.DefaultBrowsePort))
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+)
+
+// DatamoverOperationReconciler reconciles a DatamoverOperation object
+type DatamoverOperationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Used to read progress reported by client pods
+	KubeClient kubernetes.Interface
+	// Uncached reader to confirm the client pod is deleted
+	// when it's not found in the cache yet
+	APIReader client.Reader
+}
+
+// +kubebuilder:rbac:groups=dm.cr.kanister.io,resources=datamoveroperations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=dm.cr.kanister.io,resources=datamoveroperations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=dm.cr.kanister.io,resources=datamoveroperations/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources=volumesnapshots,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="coordination.k8s.io",resources=leases,verbs=get;create;update;delete
+
+// Reconcile runs the operation client pod once the session is ready
+// and records the result of the pod in the operation status.
+func (r *DatamoverOperationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+
+	dmOperation := &api.DatamoverOperation{}
+	if err := r.Get(ctx, req.NamespacedName, dmOperation); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	log.Log.Info("Read operation resource", "status", dmOperation.Status)
+	if dmOperation.DeletionTimestamp != nil {
+		return ctrl.Result{}, r.releaseSessionLease(ctx, dmOperation)
+	}
+	if operationFinished(*dmOperation) {
+		if err := r.releaseSessionLease(ctx, dmOperation); err != nil {
+			return ctrl.Result{}, err
+		}
+		switch dmOperation.Spec.Type {
+		case api.OperationTypeSnapshotBackup:
+			return ctrl.Result{}, r.cleanupSnapshot(ctx, *dmOperation)
+		case api.OperationTypeBrowse:
+			return ctrl.Result{}, r.cleanupBrowseService(ctx, *dmOperation)
+		}
+		return ctrl.Result{}, nil
+	}
+	return r.Run(ctx, dmOperation)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DatamoverOperationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&api.DatamoverOperation{}).
+		Owns(&corev1.Pod{}).
+		Complete(r)
+}
@@ -19,12 +19,19 @@ package controller
 import (
 	"context"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	api "github.com/kanisterio/datamover/api/v1alpha1"
 )
@@ -34,7 +41,10 @@ type DatamoverSessionReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	RestConfig rest.Config
+	// Limits on number of sessions running at the same time
+	Limits     ConcurrencyLimits
 	mgr        ctrl.Manager
+	admissions admissions
 }
 
 // +kubebuilder:rbac:groups=dm.cr.kanister.io,resources=datamoversessions,verbs=get;list;watch;create;update;patch;delete
@@ -45,6 +55,9 @@ type DatamoverSessionReconciler struct {
 // +kubebuilder:rbac:groups="",resources=pods/log,verbs=*
 // +kubebuilder:rbac:groups="",resources=services,verbs=*
 // +kubebuilder:rbac:groups="networking.k8s.io",resources=networkpolicies,verbs=*
+// +kubebuilder:rbac:groups="scheduling.k8s.io",resources=priorityclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="coordination.k8s.io",resources=leases,verbs=get;list;watch
+// +kubebuilder:rbac:groups="discovery.k8s.io",resources=endpointslices,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -79,5 +92,28 @@ func (r *DatamoverSessionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&api.DatamoverSession{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Pod{}).
+		// Client leases of shared sessions are owned, but not controlled by the session
+		Watches(&coordinationv1.Lease{}, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &api.DatamoverSession{})).
+		// Endpoint slices are owned by the service, but inherit the session label from it
+		Watches(&discoveryv1.EndpointSlice{}, handler.EnqueueRequestsFromMapFunc(sessionLabelRequests)).
+		// Queued sessions are admitted when an active session releases its slot
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.queuedSessionRequests),
+			builder.WithPredicates(predicate.NewPredicateFuncs(hasSessionLabel), slotReleased)).
+		Watches(&api.DatamoverSession{}, handler.EnqueueRequestsFromMapFunc(r.queuedSessionRequests),
+			builder.WithPredicates(slotReleased)).
 		Complete(r)
 }
+
+func hasSessionLabel(obj client.Object) bool {
+	return obj.GetLabels()[api.DatamoverSessionLabel] != ""
+}
+
+func sessionLabelRequests(ctx context.Context, obj client.Object) []reconcile.Request {
+	sessionName := obj.GetLabels()[api.DatamoverSessionLabel]
+	if sessionName == "" {
+		return nil
+	}
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Name: sessionName, Namespace: obj.GetNamespace()},
+	}}
+}
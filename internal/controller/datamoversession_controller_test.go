@@ -240,6 +240,14 @@ var _ = Describe("DatamoverSession Controller", func() {
 					Expect(err).NotTo(HaveOccurred())
 					Expect(service).To(BeNil())
 				})
+				It("should reject network policy changes while it's disabled", func() {
+					resource := &api.DatamoverSession{}
+					Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+					resource.Spec.LifecycleConfig.NetworkPolicy.AllowClients = true
+					err := k8sClient.Update(ctx, resource)
+					Expect(errors.IsInvalid(err)).To(BeTrue())
+					Expect(err.Error()).To(ContainSubstring("networkPolicy should be enabled to use allowClients or egress"))
+				})
 				When("Reconciled more times", func() {
 					It("should successfully reconcile", func() {
 						By("Reconciling the created resource once")
@@ -573,6 +581,13 @@ var _ = Describe("DatamoverSession Controller", func() {
 							return readiness.ready
 						}).WithPolling(1 * time.Second).WithTimeout(10 * time.Second).Should(BeTrue())
 
+						By("Waiting for service endpoints to be ready")
+						Eventually(func() bool {
+							resources, err := controllerReconciler.getResources(ctx, resource)
+							Expect(err).NotTo(HaveOccurred())
+							return resources.serviceReady
+						}).WithPolling(1 * time.Second).WithTimeout(10 * time.Second).Should(BeTrue())
+
 						By("Reconciling while resources are ready")
 						result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
 							NamespacedName: typeNamespacedName,
@@ -667,6 +682,118 @@ var _ = Describe("DatamoverSession Controller", func() {
 						Expect(resource.Status.Progress).To(Equal(api.ProgressSessionFailure))
 					})
 				})
+
+				When("Session is suspended and resumed", func() {
+					It("should delete and recreate the pod", func() {
+						By("Reconciling the created resource once")
+						result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+							NamespacedName: typeNamespacedName,
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						By("Reconciling while resources are creating")
+						result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+							NamespacedName: typeNamespacedName,
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						By("Waiting for pod to be ready")
+						Eventually(func() bool {
+							pod, err := controllerReconciler.getPod(ctx, resource)
+
+							Expect(err).NotTo(HaveOccurred())
+							Expect(pod).To(Not(BeNil()))
+							readiness, err := controllerReconciler.getReadiness(ctx, *pod)
+							Expect(err).NotTo(HaveOccurred())
+							return readiness.ready
+						}).WithPolling(1 * time.Second).WithTimeout(10 * time.Second).Should(BeTrue())
+
+						By("Waiting for service endpoints to be ready")
+						Eventually(func() bool {
+							resources, err := controllerReconciler.getResources(ctx, resource)
+							Expect(err).NotTo(HaveOccurred())
+							return resources.serviceReady
+						}).WithPolling(1 * time.Second).WithTimeout(10 * time.Second).Should(BeTrue())
+
+						By("Reconciling while resources are ready")
+						result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+							NamespacedName: typeNamespacedName,
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						By("Expecting the status to be set to Ready")
+						resource := &api.DatamoverSession{}
+						err = k8sClient.Get(ctx, typeNamespacedName, resource)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(resource.Status.Progress).To(Equal(api.ProgressReady))
+						oldPodName := resource.Status.SessionInfo.PodName
+
+						By("Suspending the session")
+						resource.Spec.Suspend = true
+						Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+						By("Reconciling suspended session")
+						result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+							NamespacedName: typeNamespacedName,
+						})
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result.Requeue).To(BeTrue())
+
+						By("Waiting for pod to be deleted")
+						Eventually(func() bool {
+							pod, err := controllerReconciler.getPod(ctx, resource)
+							Expect(err).NotTo(HaveOccurred())
+							return pod == nil
+						}).WithPolling(1 * time.Second).WithTimeout(60 * time.Second).Should(BeTrue())
+
+						By("Reconciling after pod is deleted")
+						result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+							NamespacedName: typeNamespacedName,
+						})
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result.Requeue).To(BeFalse())
+
+						By("Expecting the status to be set to Suspended")
+						resource = &api.DatamoverSession{}
+						err = k8sClient.Get(ctx, typeNamespacedName, resource)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(resource.Status.Progress).To(Equal(api.ProgressSuspended))
+
+						By("Service is still there")
+						service, err := controllerReconciler.getService(ctx, resource)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(service).To(Not(BeNil()))
+
+						By("Resuming the session")
+						resource.Spec.Suspend = false
+						Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+						By("Reconciling resumed session")
+						result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+							NamespacedName: typeNamespacedName,
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						By("Expecting the status to be reset")
+						resource = &api.DatamoverSession{}
+						err = k8sClient.Get(ctx, typeNamespacedName, resource)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(resource.Status.Progress).To(Equal(api.ProgressNone))
+						Expect(resource.Status.SessionInfo.SessionData).To(BeEmpty())
+
+						By("Reconciling to create a new pod")
+						result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+							NamespacedName: typeNamespacedName,
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						By("Creating a new pod")
+						pod, err := controllerReconciler.getPod(ctx, resource)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(pod).To(Not(BeNil()))
+						Expect(pod.Name).To(Not(Equal(oldPodName)))
+					})
+				})
 			})
 
 			When("There there is a network policy are ports", func() {
@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/kanisterio/datamover/pkg/session"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func (r *DatamoverSessionReconciler) getLeases(ctx context.Context, dmSession *api.DatamoverSession) ([]coordinationv1.Lease, error) {
+	leaseList := &coordinationv1.LeaseList{}
+	err := r.List(ctx, leaseList, client.InNamespace(dmSession.Namespace), client.MatchingLabels{api.DatamoverLeaseLabel: dmSession.Name})
+	if err != nil {
+		return nil, err
+	}
+	leases := []coordinationv1.Lease{}
+	for _, lease := range leaseList.Items {
+		// Leases of a previous session with the same name
+		for _, ref := range lease.OwnerReferences {
+			if ref.UID == dmSession.UID {
+				leases = append(leases, lease)
+				break
+			}
+		}
+	}
+	return leases, nil
+}
+
+// makeLeaseStatus counts active leases. Idle time is kept from the current status
+// so the idle timeout is not reset by each reconcile.
+func makeLeaseStatus(dmSession api.DatamoverSession, leases []coordinationv1.Lease, now time.Time) api.LeaseStatus {
+	active := activeLeases(leases, now)
+	if active > 0 {
+		return api.LeaseStatus{Active: active}
+	}
+	current := dmSession.Status.Leases
+	if current != nil && current.IdleSince != nil {
+		return api.LeaseStatus{IdleSince: current.IdleSince}
+	}
+	idleSince := metav1.NewTime(now)
+	return api.LeaseStatus{IdleSince: &idleSince}
+}
+
+func leaseStatusOutdated(dmSession api.DatamoverSession, status api.LeaseStatus) bool {
+	current := dmSession.Status.Leases
+	if current == nil {
+		return true
+	}
+	if current.Active != status.Active {
+		return true
+	}
+	if (current.IdleSince == nil) != (status.IdleSince == nil) {
+		return true
+	}
+	return current.IdleSince != nil && !current.IdleSince.Equal(status.IdleSince)
+}
+
+func idleDeadline(dmSession api.DatamoverSession, status api.LeaseStatus) (time.Time, bool) {
+	if status.Active > 0 || status.IdleSince == nil {
+		return time.Time{}, false
+	}
+	timeout := time.Duration(dmSession.Spec.Sharing.IdleTimeoutSeconds) * time.Second
+	return status.IdleSince.Add(timeout), true
+}
+
+// getSharingState returns lease related states for a shared session which did not fail
+func getSharingState(dmSession api.DatamoverSession, resources resources, now time.Time) (State, bool) {
+	if dmSession.Spec.Sharing == nil || slices.Contains(session.FailedProgress, dmSession.Status.Progress) {
+		return None, false
+	}
+	status := makeLeaseStatus(dmSession, resources.leases, now)
+	if leaseStatusOutdated(dmSession, status) {
+		return LeasesOutdated, true
+	}
+	if dmSession.Status.Progress == api.ProgressIdle {
+		return None, false
+	}
+	if deadline, ok := idleDeadline(dmSession, status); ok && !now.Before(deadline) {
+		return SessionIdle, true
+	}
+	return None, false
+}
+
+// getIdleState returns states of a shared session after the idle timeout.
+// Session is resumed once the resources are deleted and a client acquires a lease.
+func getIdleState(dmSession api.DatamoverSession, resources resources, now time.Time) State {
+	if !resourcesEmpty(resources) {
+		return IdleInProgress
+	}
+	if dmSession.Spec.Sharing == nil || activeLeases(resources.leases, now) > 0 {
+		return Resuming
+	}
+	return Idle
+}
+
+// getFinalizerState returns states managing the lease finalizer.
+// Shared session is not deleted until all leases are released or expired.
+func getFinalizerState(dmSession api.DatamoverSession, resources resources, now time.Time) (State, bool) {
+	hasFinalizer := controllerutil.ContainsFinalizer(&dmSession, api.DatamoverSessionLeaseFinalizer)
+	if dmSession.DeletionTimestamp == nil {
+		if dmSession.Spec.Sharing != nil && !hasFinalizer {
+			return LeaseFinalizerMissing, true
+		}
+		return None, false
+	}
+	if !hasFinalizer {
+		return None, false
+	}
+	if activeLeases(resources.leases, now) > 0 {
+		return DeletionWaitForLeases, true
+	}
+	return DeletionLeasesReleased, true
+}
+
+func activeLeases(leases []coordinationv1.Lease, now time.Time) int32 {
+	active := int32(0)
+	for _, lease := range leases {
+		if session.LeaseActive(lease, now) {
+			active++
+		}
+	}
+	return active
+}
+
+// nextLeaseExpiry returns when the first of the active leases expires
+func nextLeaseExpiry(leases []coordinationv1.Lease, now time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, lease := range leases {
+		if !session.LeaseActive(lease, now) {
+			continue
+		}
+		expiry := session.LeaseExpiry(lease)
+		if next.IsZero() || expiry.Before(next) {
+			next = expiry
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// nextLeaseCheck returns when shared session should be checked for expired leases or idle timeout.
+// Lease expiry does not generate events, so the session is requeued instead.
+func nextLeaseCheck(dmSession api.DatamoverSession, leases []coordinationv1.Lease, now time.Time) (time.Duration, bool) {
+	if dmSession.Spec.Sharing == nil {
+		return 0, false
+	}
+	next, _ := nextLeaseExpiry(leases, now)
+	if deadline, ok := idleDeadline(dmSession, makeLeaseStatus(dmSession, leases, now)); ok {
+		next = deadline
+	}
+	if next.IsZero() {
+		return 0, false
+	}
+	// Check slightly after the deadline
+	return next.Sub(now) + time.Second, true
+}
+
+func (r *DatamoverSessionReconciler) UpdateStatusLeases(ctx context.Context, dmSession *api.DatamoverSession, resources resources) error {
+	status := makeLeaseStatus(*dmSession, resources.leases, time.Now())
+	dmSession.Status.Leases = &status
+	if err := r.Status().Update(ctx, dmSession); err != nil {
+		// TODO: wrap error
+		return err
+	}
+	log.Log.Info("Updated session leases", "active", status.Active)
+	return nil
+}
+
+// WaitForLeases keeps the deleted session until its leases are released or expired
+func (r *DatamoverSessionReconciler) WaitForLeases(ctx context.Context, dmSession *api.DatamoverSession, resources resources) (ctrl.Result, error) {
+	now := time.Now()
+	status := makeLeaseStatus(*dmSession, resources.leases, now)
+	if leaseStatusOutdated(*dmSession, status) {
+		if err := r.UpdateStatusLeases(ctx, dmSession, resources); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	log.Log.Info("Waiting for leases to be released before deleting the session", "active", status.Active)
+	expiry, _ := nextLeaseExpiry(resources.leases, now)
+	// Check slightly after the lease expiry
+	return ctrl.Result{RequeueAfter: expiry.Sub(now) + time.Second}, nil
+}
+
+func (r *DatamoverSessionReconciler) AddLeaseFinalizer(ctx context.Context, dmSession *api.DatamoverSession) error {
+	controllerutil.AddFinalizer(dmSession, api.DatamoverSessionLeaseFinalizer)
+	return r.Update(ctx, dmSession)
+}
+
+func (r *DatamoverSessionReconciler) RemoveLeaseFinalizer(ctx context.Context, dmSession *api.DatamoverSession) error {
+	log.Log.Info("Session has no active leases, allowing deletion", "session", dmSession.Name, "namespace", dmSession.Namespace)
+	controllerutil.RemoveFinalizer(dmSession, api.DatamoverSessionLeaseFinalizer)
+	return client.IgnoreNotFound(r.Update(ctx, dmSession))
+}
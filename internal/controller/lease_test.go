@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func makeTestLease(renewed time.Time, durationSec int32) coordinationv1.Lease {
+	renewTime := metav1.NewMicroTime(renewed)
+	return coordinationv1.Lease{
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To("client"),
+			LeaseDurationSeconds: ptr.To(durationSec),
+			RenewTime:            &renewTime,
+		},
+	}
+}
+
+func makeSharedSession(idleTimeoutSec int32) api.DatamoverSession {
+	return api.DatamoverSession{
+		Spec: api.DatamoverSessionSpec{
+			Sharing: &api.SharingConfig{IdleTimeoutSeconds: idleTimeoutSec},
+		},
+	}
+}
+
+func TestSharingStateNotShared(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	_, ok := getSharingState(api.DatamoverSession{}, resources{}, time.Now())
+	matcher.Expect(ok).To(gomega.BeFalse())
+}
+
+func TestSharingStateCountsActiveLeases(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	now := time.Now()
+	dmSession := makeSharedSession(60)
+	leases := []coordinationv1.Lease{
+		makeTestLease(now, 30),
+		makeTestLease(now.Add(-time.Minute), 30),
+	}
+
+	state, ok := getSharingState(dmSession, resources{leases: leases}, now)
+	matcher.Expect(ok).To(gomega.BeTrue())
+	matcher.Expect(state).To(gomega.Equal(LeasesOutdated))
+
+	dmSession.Status.Leases = &api.LeaseStatus{Active: 1}
+	_, ok = getSharingState(dmSession, resources{leases: leases}, now)
+	matcher.Expect(ok).To(gomega.BeFalse())
+
+	// Requeued when the active lease expires
+	after, ok := nextLeaseCheck(dmSession, leases, now)
+	matcher.Expect(ok).To(gomega.BeTrue())
+	matcher.Expect(after).To(gomega.Equal(31 * time.Second))
+}
+
+func TestSharingStateIdleTimeout(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	now := time.Now()
+	dmSession := makeSharedSession(60)
+	idleSince := metav1.NewTime(now.Add(-30 * time.Second))
+	dmSession.Status.Leases = &api.LeaseStatus{IdleSince: &idleSince}
+
+	_, ok := getSharingState(dmSession, resources{}, now)
+	matcher.Expect(ok).To(gomega.BeFalse())
+	after, ok := nextLeaseCheck(dmSession, nil, now)
+	matcher.Expect(ok).To(gomega.BeTrue())
+	matcher.Expect(after).To(gomega.BeNumerically("~", 31*time.Second, time.Second))
+
+	state, ok := getSharingState(dmSession, resources{}, now.Add(time.Minute))
+	matcher.Expect(ok).To(gomega.BeTrue())
+	matcher.Expect(state).To(gomega.Equal(SessionIdle))
+}
+
+func TestSharingStateBeforeReady(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	now := time.Now()
+	dmSession := makeSharedSession(60)
+	idleSince := metav1.NewTime(now.Add(-2 * time.Minute))
+	dmSession.Status.Leases = &api.LeaseStatus{IdleSince: &idleSince}
+
+	dmSession.Status.Progress = api.ProgressQueued
+	state, ok := getSharingState(dmSession, resources{}, now)
+	matcher.Expect(ok).To(gomega.BeTrue())
+	matcher.Expect(state).To(gomega.Equal(SessionIdle))
+
+	// Failed sessions are kept to trace errors
+	dmSession.Status.Progress = api.ProgressSessionFailure
+	_, ok = getSharingState(dmSession, resources{}, now)
+	matcher.Expect(ok).To(gomega.BeFalse())
+
+	dmSession.Status.Progress = api.ProgressIdle
+	_, ok = getSharingState(dmSession, resources{}, now)
+	matcher.Expect(ok).To(gomega.BeFalse())
+}
+
+func TestIdleState(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	now := time.Now()
+	dmSession := makeSharedSession(60)
+	dmSession.Status.Progress = api.ProgressIdle
+
+	matcher.Expect(getIdleState(dmSession, resources{pod: &corev1.Pod{}}, now)).To(gomega.Equal(IdleInProgress))
+	matcher.Expect(getIdleState(dmSession, resources{}, now)).To(gomega.Equal(Idle))
+	leases := []coordinationv1.Lease{makeTestLease(now, 30)}
+	matcher.Expect(getIdleState(dmSession, resources{leases: leases}, now)).To(gomega.Equal(Resuming))
+}
+
+func TestFinalizerState(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	now := time.Now()
+	dmSession := makeSharedSession(60)
+
+	state, ok := getFinalizerState(dmSession, resources{}, now)
+	matcher.Expect(ok).To(gomega.BeTrue())
+	matcher.Expect(state).To(gomega.Equal(LeaseFinalizerMissing))
+
+	dmSession.Finalizers = []string{api.DatamoverSessionLeaseFinalizer}
+	_, ok = getFinalizerState(dmSession, resources{}, now)
+	matcher.Expect(ok).To(gomega.BeFalse())
+
+	deleted := metav1.NewTime(now)
+	dmSession.DeletionTimestamp = &deleted
+	leases := []coordinationv1.Lease{makeTestLease(now, 30)}
+	state, _ = getFinalizerState(dmSession, resources{leases: leases}, now)
+	matcher.Expect(state).To(gomega.Equal(DeletionWaitForLeases))
+
+	// Expired leases do not block deletion
+	state, _ = getFinalizerState(dmSession, resources{leases: leases}, now.Add(time.Minute))
+	matcher.Expect(state).To(gomega.Equal(DeletionLeasesReleased))
+
+	// Finalizer is removed after sharing is disabled
+	dmSession.Spec.Sharing = nil
+	state, _ = getFinalizerState(dmSession, resources{}, now)
+	matcher.Expect(state).To(gomega.Equal(DeletionLeasesReleased))
+}
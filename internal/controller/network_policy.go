@@ -2,11 +2,13 @@ package controller
 
 import (
 	"context"
+	"slices"
 
 	api "github.com/kanisterio/datamover/api/v1alpha1"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -36,11 +38,39 @@ func (r *DatamoverSessionReconciler) CreateNetworkPolicy(ctx context.Context, dm
 	return nil
 }
 
+// UpdateNetworkPolicy applies changes in the session network policy config
+// to the existing network policy
+func (r *DatamoverSessionReconciler) UpdateNetworkPolicy(ctx context.Context, dmSession api.DatamoverSession, np *networkingv1.NetworkPolicy) error {
+	if np == nil {
+		return errors.New("Network policy should exist to be updated")
+	}
+	np.Spec = makeNetworkPolicySpec(dmSession).Spec
+	if err := r.Update(ctx, np); err != nil {
+		return errors.Wrap(err, "Failed to update network policy")
+	}
+	log.Log.Info("Updated network policy.")
+	return nil
+}
+
+func networkPolicyOutdated(np networkingv1.NetworkPolicy, dmSession api.DatamoverSession) bool {
+	desired := makeNetworkPolicySpec(dmSession)
+	return !equality.Semantic.DeepEqual(np.Spec.Ingress, desired.Spec.Ingress) ||
+		!equality.Semantic.DeepEqual(np.Spec.Egress, desired.Spec.Egress) ||
+		!equality.Semantic.DeepEqual(np.Spec.PolicyTypes, desired.Spec.PolicyTypes)
+}
+
 func (r *DatamoverSessionReconciler) DeleteNetworkPolicy(ctx context.Context, service *corev1.Service) error {
 	return r.Delete(ctx, service)
 }
 
 func makeNetworkPolicySpec(dmSession api.DatamoverSession) networkingv1.NetworkPolicy {
+	config := dmSession.Spec.LifecycleConfig.NetworkPolicy
+	policyTypes := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+	var egress []networkingv1.NetworkPolicyEgressRule
+	if config.Egress != nil {
+		policyTypes = append(policyTypes, networkingv1.PolicyTypeEgress)
+		egress = networkPolicyEgress(*config.Egress)
+	}
 	return networkingv1.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      dmSession.Name,
@@ -60,12 +90,50 @@ func makeNetworkPolicySpec(dmSession api.DatamoverSession) networkingv1.NetworkP
 			},
 			Ingress: []networkingv1.NetworkPolicyIngressRule{
 				{
-					From:  dmSession.Spec.LifecycleConfig.NetworkPolicy.From,
+					From:  networkPolicyFrom(dmSession),
 					Ports: networkPolicyPorts(dmSession),
 				},
 			},
+			Egress:      egress,
+			PolicyTypes: policyTypes,
+		},
+	}
+}
+
+func networkPolicyFrom(dmSession api.DatamoverSession) []networkingv1.NetworkPolicyPeer {
+	config := dmSession.Spec.LifecycleConfig.NetworkPolicy
+	from := slices.Clone(config.From)
+	if config.AllowClients {
+		// Without namespace selector only pods from the policy namespace are selected
+		from = append(from, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{api.DatamoverClientLabel: string(dmSession.UID)},
+			},
+			NamespaceSelector: config.ClientNamespaceSelector,
+		})
+	}
+	return from
+}
+
+func networkPolicyEgress(config api.EgressPolicyConfig) []networkingv1.NetworkPolicyEgressRule {
+	rules := []networkingv1.NetworkPolicyEgressRule{
+		{
+			To:    config.To,
+			Ports: config.Ports,
 		},
 	}
+	if !config.DenyDNS {
+		udp := corev1.ProtocolUDP
+		tcp := corev1.ProtocolTCP
+		dnsPort := intstr.FromInt32(53)
+		rules = append(rules, networkingv1.NetworkPolicyEgressRule{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			},
+		})
+	}
+	return rules
 }
 
 func networkPolicyPorts(dmSession api.DatamoverSession) []networkingv1.NetworkPolicyPort {
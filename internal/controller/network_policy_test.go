@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"testing"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestNetworkPolicyOutdatedOnPeersChange(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	dmSession := api.DatamoverSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_datamover",
+			Namespace: "foo_namespace",
+		},
+		Spec: api.DatamoverSessionSpec{
+			Implementation: "foo_impl",
+			LifecycleConfig: &api.LifecycleConfig{
+				Image: "foo_image",
+				ServicePorts: []corev1.ServicePort{
+					{Name: "foo_protocol", Port: 2000, Protocol: corev1.ProtocolTCP},
+				},
+				NetworkPolicy: api.NetworkPolicyConfig{
+					Enabled: true,
+					From: []networkingv1.NetworkPolicyPeer{{
+						PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "client"}},
+					}},
+				},
+			},
+		},
+	}
+	np := makeNetworkPolicySpec(dmSession)
+	matcher.Expect(networkPolicyOutdated(np, dmSession)).To(gomega.BeFalse())
+
+	dmSession.Spec.LifecycleConfig.NetworkPolicy.From = append(dmSession.Spec.LifecycleConfig.NetworkPolicy.From,
+		networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "backup"}},
+		})
+	matcher.Expect(networkPolicyOutdated(np, dmSession)).To(gomega.BeTrue())
+
+	updated := makeNetworkPolicySpec(dmSession)
+	matcher.Expect(updated.Spec.Ingress[0].From).To(gomega.HaveLen(2))
+	matcher.Expect(networkPolicyOutdated(updated, dmSession)).To(gomega.BeFalse())
+}
+
+func TestNetworkPolicyAllowClients(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	dmSession := api.DatamoverSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_datamover",
+			Namespace: "foo_namespace",
+			UID:       "foo_uid",
+		},
+		Spec: api.DatamoverSessionSpec{
+			Implementation: "foo_impl",
+			LifecycleConfig: &api.LifecycleConfig{
+				Image: "foo_image",
+				ServicePorts: []corev1.ServicePort{
+					{Name: "foo_protocol", Port: 2000, Protocol: corev1.ProtocolTCP},
+				},
+				NetworkPolicy: api.NetworkPolicyConfig{
+					Enabled:      true,
+					AllowClients: true,
+				},
+			},
+		},
+	}
+	np := makeNetworkPolicySpec(dmSession)
+	matcher.Expect(np.Spec.PolicyTypes).To(gomega.Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeIngress}))
+	matcher.Expect(np.Spec.Ingress).To(gomega.HaveLen(1))
+	matcher.Expect(np.Spec.Ingress[0].From).To(gomega.Equal([]networkingv1.NetworkPolicyPeer{{
+		PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{api.DatamoverClientLabel: "foo_uid"}},
+	}}))
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "client_namespace"}}
+	dmSession.Spec.LifecycleConfig.NetworkPolicy.ClientNamespaceSelector = selector
+	np = makeNetworkPolicySpec(dmSession)
+	matcher.Expect(np.Spec.Ingress[0].From[0].NamespaceSelector).To(gomega.Equal(selector))
+}
+
+func TestNetworkPolicyEgress(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	storagePort := intstr.FromInt32(443)
+	dmSession := api.DatamoverSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_datamover",
+			Namespace: "foo_namespace",
+		},
+		Spec: api.DatamoverSessionSpec{
+			Implementation: "foo_impl",
+			LifecycleConfig: &api.LifecycleConfig{
+				Image: "foo_image",
+				ServicePorts: []corev1.ServicePort{
+					{Name: "foo_protocol", Port: 2000, Protocol: corev1.ProtocolTCP},
+				},
+				NetworkPolicy: api.NetworkPolicyConfig{
+					Enabled: true,
+					Egress: &api.EgressPolicyConfig{
+						To:    []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/24"}}},
+						Ports: []networkingv1.NetworkPolicyPort{{Port: &storagePort}},
+					},
+				},
+			},
+		},
+	}
+	np := makeNetworkPolicySpec(dmSession)
+	matcher.Expect(np.Spec.PolicyTypes).To(gomega.ConsistOf(networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress))
+	// Storage endpoint and DNS
+	matcher.Expect(np.Spec.Egress).To(gomega.HaveLen(2))
+	matcher.Expect(np.Spec.Egress[0].To[0].IPBlock.CIDR).To(gomega.Equal("10.0.0.0/24"))
+	matcher.Expect(np.Spec.Egress[1].Ports).To(gomega.HaveLen(2))
+
+	dmSession.Spec.LifecycleConfig.NetworkPolicy.Egress.DenyDNS = true
+	matcher.Expect(networkPolicyOutdated(np, dmSession)).To(gomega.BeTrue())
+	np = makeNetworkPolicySpec(dmSession)
+	matcher.Expect(np.Spec.Egress).To(gomega.HaveLen(1))
+}
@@ -0,0 +1,322 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	dmclient "github.com/kanisterio/datamover/pkg/client"
+	"github.com/kanisterio/datamover/pkg/session"
+)
+
+const (
+	defaultOperationBackoffLimit = 3
+	// Interval to check the session while operation is pending
+	sessionRecheckInterval = time.Second * 5
+)
+
+// Run starts a client pod for the current attempt, waits for it to finish
+// and retries transient failures up to the backoff limit.
+func (r *DatamoverOperationReconciler) Run(ctx context.Context, dmOperation *api.DatamoverOperation) (ctrl.Result, error) {
+	pod, err := r.getOperationPod(ctx, *dmOperation)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if pod == nil {
+		if dmOperation.Status.Phase == api.OperationPhaseRunning {
+			return ctrl.Result{}, r.retryOrFail(ctx, dmOperation, nil, "Client pod was deleted")
+		}
+		return r.startAttempt(ctx, dmOperation)
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		result, err := dmclient.ParseResult(*pod)
+		if err != nil {
+			return ctrl.Result{}, r.UpdateStatusFailed(ctx, dmOperation, err.Error())
+		}
+		return ctrl.Result{}, r.UpdateStatusSucceeded(ctx, dmOperation, result)
+	case corev1.PodFailed:
+		message := dmclient.ClassifyFailure(*pod).Error()
+		if !retriableFailure(*pod) {
+			return ctrl.Result{}, r.UpdateStatusFailed(ctx, dmOperation, message)
+		}
+		return ctrl.Result{}, r.retryOrFail(ctx, dmOperation, pod, message)
+	default:
+		renewAfter, err := r.keepSessionLease(ctx, dmOperation)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		// Pod updates trigger reconcile, requeue is only needed to renew the lease or check progress
+		result := ctrl.Result{RequeueAfter: renewAfter}
+		if dmOperation.Spec.ProgressIntervalSeconds > 0 && pod.Status.Phase == corev1.PodRunning {
+			progressResult, err := r.updateProgress(ctx, dmOperation, pod)
+			return earliestRequeue(result, progressResult), err
+		}
+		return result, nil
+	}
+}
+
+func earliestRequeue(a, b ctrl.Result) ctrl.Result {
+	if a.RequeueAfter == 0 || (b.RequeueAfter > 0 && b.RequeueAfter < a.RequeueAfter) {
+		return b
+	}
+	return a
+}
+
+// updateProgress records the last progress reported by the client pod
+// and requeues to check it again after the progress interval
+func (r *DatamoverOperationReconciler) updateProgress(ctx context.Context, dmOperation *api.DatamoverOperation, pod *corev1.Pod) (ctrl.Result, error) {
+	requeue := ctrl.Result{RequeueAfter: time.Duration(dmOperation.Spec.ProgressIntervalSeconds) * time.Second}
+	progress, err := dmclient.GetProgress(ctx, r.KubeClient, pod)
+	if err != nil {
+		// Progress is informational, operation continues without it
+		log.Log.Info("Unable to read operation progress", "pod", pod.Name, "error", err.Error())
+		return requeue, nil
+	}
+	if progress == nil || equality.Semantic.DeepEqual(progress, dmOperation.Status.Progress) {
+		return requeue, nil
+	}
+	dmOperation.Status.Progress = progress
+	return requeue, r.updateStatus(ctx, dmOperation)
+}
+
+// startAttempt creates a client pod once the session is ready
+func (r *DatamoverOperationReconciler) startAttempt(ctx context.Context, dmOperation *api.DatamoverOperation) (ctrl.Result, error) {
+	dmSession := &api.DatamoverSession{}
+	err := r.Get(ctx, types.NamespacedName{Name: dmOperation.Spec.SessionRef.Name, Namespace: dmOperation.Namespace}, dmSession)
+	if apierrors.IsNotFound(err) {
+		err := r.UpdateStatusPending(ctx, dmOperation, "Waiting for session to be created")
+		return ctrl.Result{RequeueAfter: sessionRecheckInterval}, err
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if dmSession.DeletionTimestamp != nil || session.IsTerminated(*dmSession) {
+		return ctrl.Result{}, r.UpdateStatusFailed(ctx, dmOperation, "Session is terminated: "+string(dmSession.Status.Progress))
+	}
+	// Lease is acquired before the session is ready to resume idle shared sessions
+	renewAfter, err := r.holdSessionLease(ctx, dmOperation, *dmSession)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if dmSession.Status.Progress != api.ProgressReady {
+		err := r.UpdateStatusPending(ctx, dmOperation, "Waiting for session to be ready")
+		return ctrl.Result{RequeueAfter: sessionRecheckInterval}, err
+	}
+
+	if dmOperation.Spec.Type == api.OperationTypeSnapshotBackup && dmOperation.Spec.SnapshotBackup != nil {
+		message, err := r.prepareSnapshot(ctx, dmOperation)
+		var snapshotErr snapshotFailedError
+		if errors.As(err, &snapshotErr) {
+			return ctrl.Result{}, r.retrySnapshot(ctx, dmOperation, snapshotErr.Error())
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if message != "" {
+			err := r.UpdateStatusPending(ctx, dmOperation, message)
+			return ctrl.Result{RequeueAfter: sessionRecheckInterval}, err
+		}
+	}
+
+	if dmOperation.Spec.Type == api.OperationTypeFsRestore && dmOperation.Spec.FsRestore != nil {
+		dmOperation.Status.PVCName = dmclient.RestorePVCName(*dmOperation)
+		if dmOperation.Spec.FsRestore.PVCTemplate != nil {
+			message, err := r.provisionRestorePVC(ctx, dmOperation)
+			var pendingErr backupPendingError
+			if errors.As(err, &pendingErr) {
+				err := r.UpdateStatusPending(ctx, dmOperation, pendingErr.Error())
+				return ctrl.Result{RequeueAfter: sessionRecheckInterval}, err
+			}
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if message != "" {
+				return ctrl.Result{}, r.UpdateStatusFailed(ctx, dmOperation, message)
+			}
+		}
+	}
+
+	if dmOperation.Spec.Type == api.OperationTypeBrowse && dmOperation.Spec.Browse != nil {
+		if err := r.createBrowseNetworkPolicy(ctx, dmOperation); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.createBrowseService(ctx, dmOperation); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	var service *corev1.Service
+	if dmSession.Status.SessionInfo.ServiceName != "" {
+		service = &corev1.Service{}
+		err := r.Get(ctx, types.NamespacedName{Name: dmSession.Status.SessionInfo.ServiceName, Namespace: dmSession.Namespace}, service)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "Unable to get session service")
+		}
+	}
+
+	attempt := dmOperation.Status.Attempts + 1
+	pod, err := r.makeOperationPod(*dmOperation, *session.MakeConfig(*dmSession, service), attempt)
+	if err != nil {
+		log.Log.Error(err, "Operation validation failed")
+		return ctrl.Result{}, r.UpdateStatusFailed(ctx, dmOperation, err.Error())
+	}
+	// Pod name is derived from the attempt, so it already exists
+	// if the status update failed after creating it
+	if err := r.Create(ctx, pod); err != nil && !apierrors.IsAlreadyExists(err) {
+		return ctrl.Result{}, errors.Wrap(err, "Unable to create client pod")
+	}
+	if err := dmclient.CheckProgressSidecar(pod.Spec); err != nil {
+		// Pod would never start
+		if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, errors.Wrap(err, "Unable to delete client pod")
+		}
+		return ctrl.Result{}, r.UpdateStatusFailed(ctx, dmOperation, err.Error())
+	}
+	return ctrl.Result{RequeueAfter: renewAfter}, r.UpdateStatusRunning(ctx, dmOperation, pod.Name, attempt)
+}
+
+func (r *DatamoverOperationReconciler) makeOperationPod(dmOperation api.DatamoverOperation, sessionConfig session.SessionConfig, attempt int32) (*corev1.Pod, error) {
+	clientArgs, err := dmclient.ClientArgsFromOperation(dmOperation)
+	if err != nil {
+		return nil, err
+	}
+	pod, err := dmclient.MakeClientPod(clientArgs, sessionConfig)
+	if err != nil {
+		return nil, err
+	}
+	pod.GenerateName = ""
+	pod.Name = operationPodName(dmOperation, attempt)
+	labels := maps.Clone(pod.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[api.DatamoverOperationLabel] = dmOperation.Name
+	pod.Labels = labels
+	if err := ctrl.SetControllerReference(&dmOperation, pod, r.Scheme); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+func operationPodName(dmOperation api.DatamoverOperation, attempt int32) string {
+	return fmt.Sprintf("%s-%d", dmOperation.Name, attempt)
+}
+
+func (r *DatamoverOperationReconciler) getOperationPod(ctx context.Context, dmOperation api.DatamoverOperation) (*corev1.Pod, error) {
+	if dmOperation.Status.PodName == "" {
+		return nil, nil
+	}
+	pod := &corev1.Pod{}
+	key := types.NamespacedName{Name: dmOperation.Status.PodName, Namespace: dmOperation.Namespace}
+	err := r.Get(ctx, key, pod)
+	if apierrors.IsNotFound(err) {
+		// Pod created by the previous reconcile may not be in the cache yet,
+		// starting a new attempt would run the operation twice
+		err = r.APIReader.Get(ctx, key, pod)
+	}
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !metav1.IsControlledBy(pod, &dmOperation) {
+		return nil, fmt.Errorf("Found pod %s not matching owner reference of the operation %s", pod.Name, dmOperation.Name)
+	}
+	return pod, nil
+}
+
+// retryOrFail deletes the failed pod and resets the status to start a new attempt,
+// or fails the operation if there are no retries left
+func (r *DatamoverOperationReconciler) retryOrFail(ctx context.Context, dmOperation *api.DatamoverOperation, pod *corev1.Pod, message string) error {
+	if dmOperation.Status.Attempts > operationBackoffLimit(*dmOperation) {
+		return r.UpdateStatusFailed(ctx, dmOperation, fmt.Sprintf("%s, giving up after %d attempts", message, dmOperation.Status.Attempts))
+	}
+	if pod != nil {
+		if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "Unable to delete failed client pod")
+		}
+	}
+	log.Log.Info("Retrying operation", "attempts", dmOperation.Status.Attempts, "message", message)
+	dmOperation.Status.Phase = api.OperationPhasePending
+	dmOperation.Status.PodName = ""
+	dmOperation.Status.Progress = nil
+	dmOperation.Status.Message = message
+	return r.updateStatus(ctx, dmOperation)
+}
+
+func operationBackoffLimit(dmOperation api.DatamoverOperation) int32 {
+	if dmOperation.Spec.BackoffLimit == nil {
+		return defaultOperationBackoffLimit
+	}
+	return *dmOperation.Spec.BackoffLimit
+}
+
+func operationFinished(dmOperation api.DatamoverOperation) bool {
+	phase := dmOperation.Status.Phase
+	return phase == api.OperationPhaseSucceeded || phase == api.OperationPhaseFailed
+}
+
+// retriableFailure returns false if the client reported invalid configuration
+func retriableFailure(pod corev1.Pod) bool {
+	terminated := dmclient.MainContainerTerminated(pod)
+	return terminated == nil || terminated.ExitCode != dmclient.InvalidConfigExitCode
+}
+
+func (r *DatamoverOperationReconciler) UpdateStatusPending(ctx context.Context, dmOperation *api.DatamoverOperation, message string) error {
+	if dmOperation.Status.Phase == api.OperationPhasePending && dmOperation.Status.Message == message {
+		return nil
+	}
+	dmOperation.Status.Phase = api.OperationPhasePending
+	dmOperation.Status.Message = message
+	return r.updateStatus(ctx, dmOperation)
+}
+
+func (r *DatamoverOperationReconciler) UpdateStatusRunning(ctx context.Context, dmOperation *api.DatamoverOperation, podName string, attempt int32) error {
+	dmOperation.Status.Phase = api.OperationPhaseRunning
+	dmOperation.Status.PodName = podName
+	dmOperation.Status.Attempts = attempt
+	if dmOperation.Status.StartTime == nil {
+		now := metav1.Now()
+		dmOperation.Status.StartTime = &now
+	}
+	return r.updateStatus(ctx, dmOperation)
+}
+
+func (r *DatamoverOperationReconciler) UpdateStatusSucceeded(ctx context.Context, dmOperation *api.DatamoverOperation, result *api.OperationResult) error {
+	now := metav1.Now()
+	dmOperation.Status.Phase = api.OperationPhaseSucceeded
+	dmOperation.Status.Result = result
+	dmOperation.Status.Message = ""
+	dmOperation.Status.CompletionTime = &now
+	return r.updateStatus(ctx, dmOperation)
+}
+
+func (r *DatamoverOperationReconciler) UpdateStatusFailed(ctx context.Context, dmOperation *api.DatamoverOperation, message string) error {
+	now := metav1.Now()
+	dmOperation.Status.Phase = api.OperationPhaseFailed
+	dmOperation.Status.Message = message
+	dmOperation.Status.CompletionTime = &now
+	return r.updateStatus(ctx, dmOperation)
+}
+
+func (r *DatamoverOperationReconciler) updateStatus(ctx context.Context, dmOperation *api.DatamoverOperation) error {
+	if err := r.Status().Update(ctx, dmOperation); err != nil {
+		return errors.Wrap(err, "Unable to update operation status")
+	}
+	log.Log.Info("Updated operation status", "phase", dmOperation.Status.Phase, "message", dmOperation.Status.Message)
+	return nil
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/kanisterio/datamover/pkg/session"
+)
+
+const (
+	operationLeaseDuration = time.Minute
+	// Lease is renewed well before it expires
+	operationLeaseRenewInterval = operationLeaseDuration / 3
+)
+
+// holdSessionLease acquires or renews the lease on a shared session, so the session
+// is kept running, or resumed if idle, while the operation uses it.
+// Returns when the lease should be renewed, zero if the session is not shared.
+func (r *DatamoverOperationReconciler) holdSessionLease(ctx context.Context, dmOperation *api.DatamoverOperation, dmSession api.DatamoverSession) (time.Duration, error) {
+	if dmSession.Spec.Sharing == nil {
+		return 0, nil
+	}
+	if dmOperation.Status.LeaseName != "" {
+		lease, err := r.KubeClient.CoordinationV1().Leases(dmOperation.Namespace).Get(ctx, dmOperation.Status.LeaseName, metav1.GetOptions{})
+		if err == nil {
+			return r.renewSessionLease(ctx, lease)
+		}
+		if !apierrors.IsNotFound(err) {
+			return 0, errors.Wrap(err, "Unable to get session lease")
+		}
+		log.Log.Info("Session lease was deleted, acquiring a new one", "lease", dmOperation.Status.LeaseName)
+	}
+	// Lease is owned by the session, so it has to be released before the operation is deleted
+	if !controllerutil.ContainsFinalizer(dmOperation, api.DatamoverOperationLeaseFinalizer) {
+		controllerutil.AddFinalizer(dmOperation, api.DatamoverOperationLeaseFinalizer)
+		if err := r.Update(ctx, dmOperation); err != nil {
+			return 0, errors.Wrap(err, "Unable to add session lease finalizer")
+		}
+	}
+	lease, err := session.Acquire(ctx, r.KubeClient, dmSession, operationLeaseHolder(*dmOperation), operationLeaseDuration)
+	if err != nil {
+		return 0, err
+	}
+	log.Log.Info("Acquired session lease", "lease", lease.Name, "session", dmSession.Name)
+	dmOperation.Status.LeaseName = lease.Name
+	if err := r.updateStatus(ctx, dmOperation); err != nil {
+		return 0, err
+	}
+	return operationLeaseRenewInterval, nil
+}
+
+func (r *DatamoverOperationReconciler) renewSessionLease(ctx context.Context, lease *coordinationv1.Lease) (time.Duration, error) {
+	renewTime := lease.CreationTimestamp.Time
+	if lease.Spec.RenewTime != nil {
+		renewTime = lease.Spec.RenewTime.Time
+	}
+	if after := time.Until(renewTime.Add(operationLeaseRenewInterval)); after > 0 {
+		return after, nil
+	}
+	if _, err := session.Renew(ctx, r.KubeClient, lease); err != nil {
+		return 0, err
+	}
+	return operationLeaseRenewInterval, nil
+}
+
+// keepSessionLease renews the lease of the running operation
+func (r *DatamoverOperationReconciler) keepSessionLease(ctx context.Context, dmOperation *api.DatamoverOperation) (time.Duration, error) {
+	if dmOperation.Status.LeaseName == "" {
+		return 0, nil
+	}
+	dmSession := &api.DatamoverSession{}
+	err := r.Get(ctx, types.NamespacedName{Name: dmOperation.Spec.SessionRef.Name, Namespace: dmOperation.Namespace}, dmSession)
+	if apierrors.IsNotFound(err) {
+		// Leases are deleted with the session
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return r.holdSessionLease(ctx, dmOperation, *dmSession)
+}
+
+// releaseSessionLease releases the lease of the finished or deleted operation
+// and removes the lease finalizer
+func (r *DatamoverOperationReconciler) releaseSessionLease(ctx context.Context, dmOperation *api.DatamoverOperation) error {
+	if dmOperation.Status.LeaseName != "" {
+		lease := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: dmOperation.Status.LeaseName, Namespace: dmOperation.Namespace}}
+		if err := session.Release(ctx, r.KubeClient, lease); err != nil {
+			return err
+		}
+		log.Log.Info("Released session lease", "lease", lease.Name)
+		dmOperation.Status.LeaseName = ""
+		if err := r.updateStatus(ctx, dmOperation); err != nil {
+			return err
+		}
+	}
+	if !controllerutil.ContainsFinalizer(dmOperation, api.DatamoverOperationLeaseFinalizer) {
+		return nil
+	}
+	controllerutil.RemoveFinalizer(dmOperation, api.DatamoverOperationLeaseFinalizer)
+	if err := client.IgnoreNotFound(r.Update(ctx, dmOperation)); err != nil {
+		return errors.Wrap(err, "Unable to remove session lease finalizer")
+	}
+	return nil
+}
+
+func operationLeaseHolder(dmOperation api.DatamoverOperation) string {
+	return "datamoveroperation/" + dmOperation.Name
+}
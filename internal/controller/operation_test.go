@@ -0,0 +1,316 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	dmclient "github.com/kanisterio/datamover/pkg/client"
+	"github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func makeOperationReconciler(t *testing.T, objects ...runtime.Object) *DatamoverOperationReconciler {
+	scheme := runtime.NewScheme()
+	gomega.NewWithT(t).Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	gomega.NewWithT(t).Expect(api.AddToScheme(scheme)).To(gomega.Succeed())
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRuntimeObjects(objects...).
+		WithStatusSubresource(&api.DatamoverOperation{}, &api.DatamoverSession{}).
+		Build()
+	return &DatamoverOperationReconciler{Client: cli, Scheme: scheme, KubeClient: kubefake.NewSimpleClientset(), APIReader: cli}
+}
+
+func makeOperation(backoffLimit int32) *api.DatamoverOperation {
+	return &api.DatamoverOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "ns", UID: "operation-uid"},
+		Spec: api.DatamoverOperationSpec{
+			SessionRef:   corev1.LocalObjectReference{Name: "session"},
+			Type:         api.OperationTypeFsBackup,
+			Image:        "client",
+			FsBackup:     &api.FsBackupParams{PVC: "data", Path: "/"},
+			BackoffLimit: &backoffLimit,
+		},
+	}
+}
+
+func makeOperationSession(progress api.DatamoverSessionProgress) *api.DatamoverSession {
+	return &api.DatamoverSession{
+		ObjectMeta: metav1.ObjectMeta{Name: "session", Namespace: "ns", UID: "session-uid"},
+		Spec:       api.DatamoverSessionSpec{Implementation: "kopia"},
+		Status:     api.DatamoverSessionStatus{Progress: progress},
+	}
+}
+
+func reconcileOperation(t *testing.T, r *DatamoverOperationReconciler) api.DatamoverOperation {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	name := types.NamespacedName{Name: "backup", Namespace: "ns"}
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	dmOperation := api.DatamoverOperation{}
+	matcher.Expect(r.Get(ctx, name, &dmOperation)).To(gomega.Succeed())
+	return dmOperation
+}
+
+func finishPod(t *testing.T, r *DatamoverOperationReconciler, podName string, phase corev1.PodPhase, exitCode int32, message string) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	pod := corev1.Pod{}
+	matcher.Expect(r.Get(ctx, types.NamespacedName{Name: podName, Namespace: "ns"}, &pod)).To(gomega.Succeed())
+	pod.Status.Phase = phase
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name: dmclient.MainContainerName,
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ExitCode: exitCode,
+			Message:  message,
+		}},
+	}}
+	matcher.Expect(r.Status().Update(ctx, &pod)).To(gomega.Succeed())
+}
+
+func TestOperationWaitsForSession(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	r := makeOperationReconciler(t, makeOperation(3), makeOperationSession(api.ProgressResourcesCreated))
+
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhasePending))
+	matcher.Expect(dmOperation.Status.PodName).To(gomega.BeEmpty())
+}
+
+func TestOperationFailsOnFailedSession(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	r := makeOperationReconciler(t, makeOperation(3), makeOperationSession(api.ProgressSessionFailure))
+
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseFailed))
+}
+
+func TestOperationRetriesAndSucceeds(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	r := makeOperationReconciler(t, makeOperation(3), makeOperationSession(api.ProgressReady))
+
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseRunning))
+	matcher.Expect(dmOperation.Status.PodName).To(gomega.Equal("backup-1"))
+	matcher.Expect(dmOperation.Status.Attempts).To(gomega.BeEquivalentTo(1))
+
+	pod := corev1.Pod{}
+	matcher.Expect(r.Get(context.Background(), types.NamespacedName{Name: "backup-1", Namespace: "ns"}, &pod)).To(gomega.Succeed())
+	matcher.Expect(pod.Labels).To(gomega.HaveKeyWithValue(api.DatamoverOperationLabel, "backup"))
+	matcher.Expect(pod.Labels).To(gomega.HaveKeyWithValue(api.DatamoverClientLabel, "session-uid"))
+
+	// Transient failure deletes the pod and starts a new attempt
+	finishPod(t, r, "backup-1", corev1.PodFailed, 1, "connection refused")
+	dmOperation = reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhasePending))
+	matcher.Expect(dmOperation.Status.Message).To(gomega.ContainSubstring("connection refused"))
+
+	dmOperation = reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.PodName).To(gomega.Equal("backup-2"))
+
+	finishPod(t, r, "backup-2", corev1.PodSucceeded, 0, `{"snapshotID":"snapshot-id","sizeBytes":1024,"files":3,"duration":"1m0s"}`)
+	dmOperation = reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseSucceeded))
+	matcher.Expect(dmOperation.Status.Result.SnapshotID).To(gomega.Equal("snapshot-id"))
+	matcher.Expect(dmOperation.Status.Result.SizeBytes).To(gomega.BeEquivalentTo(1024))
+	matcher.Expect(dmOperation.Status.CompletionTime).ToNot(gomega.BeNil())
+}
+
+func TestOperationPodNotInCache(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	r := makeOperationReconciler(t, makeOperation(3), makeOperationSession(api.ProgressReady))
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.PodName).To(gomega.Equal("backup-1"))
+
+	// Pod exists on the API server, but the cache has not seen it yet
+	apiServer := r.Client
+	cache := fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithRuntimeObjects(&dmOperation, makeOperationSession(api.ProgressReady)).
+		WithStatusSubresource(&api.DatamoverOperation{}, &api.DatamoverSession{}).
+		Build()
+	r.Client = cache
+	r.APIReader = apiServer
+
+	dmOperation = reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseRunning))
+	matcher.Expect(dmOperation.Status.PodName).To(gomega.Equal("backup-1"))
+	matcher.Expect(dmOperation.Status.Attempts).To(gomega.BeEquivalentTo(1))
+	dmOperation = reconcileOperation(t, r)
+	matcher.Expect(apierrors.IsNotFound(apiServer.Get(ctx, types.NamespacedName{Name: "backup-2", Namespace: "ns"}, &corev1.Pod{}))).To(gomega.BeTrue())
+	matcher.Expect(apierrors.IsNotFound(cache.Get(ctx, types.NamespacedName{Name: "backup-2", Namespace: "ns"}, &corev1.Pod{}))).To(gomega.BeTrue())
+
+	// Pod deleted from the API server starts a new attempt
+	pod := &corev1.Pod{}
+	matcher.Expect(apiServer.Get(ctx, types.NamespacedName{Name: "backup-1", Namespace: "ns"}, pod)).To(gomega.Succeed())
+	matcher.Expect(apiServer.Delete(ctx, pod)).To(gomega.Succeed())
+	dmOperation = reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhasePending))
+	matcher.Expect(dmOperation.Status.Message).To(gomega.Equal("Client pod was deleted"))
+}
+
+func TestOperationDoesNotRetryInvalidConfig(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	r := makeOperationReconciler(t, makeOperation(3), makeOperationSession(api.ProgressReady))
+
+	reconcileOperation(t, r)
+	finishPod(t, r, "backup-1", corev1.PodFailed, dmclient.InvalidConfigExitCode, `{"errors":["invalid repository"]}`)
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseFailed))
+	matcher.Expect(dmOperation.Status.Attempts).To(gomega.BeEquivalentTo(1))
+	matcher.Expect(dmOperation.Status.Message).To(gomega.HaveSuffix(": invalid repository"))
+}
+
+func TestOperationBackoffLimit(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	r := makeOperationReconciler(t, makeOperation(0), makeOperationSession(api.ProgressReady))
+
+	reconcileOperation(t, r)
+	finishPod(t, r, "backup-1", corev1.PodFailed, 1, "")
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseFailed))
+	matcher.Expect(dmOperation.Status.Message).To(gomega.ContainSubstring("giving up after 1 attempts"))
+}
+
+func TestOperationRequeuesProgress(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	dmOperation := makeOperation(3)
+	dmOperation.Spec.ProgressIntervalSeconds = 10
+	r := makeOperationReconciler(t, dmOperation, makeOperationSession(api.ProgressReady))
+	reconcileOperation(t, r)
+
+	pod := corev1.Pod{}
+	matcher.Expect(r.Get(ctx, types.NamespacedName{Name: "backup-1", Namespace: "ns"}, &pod)).To(gomega.Succeed())
+	pod.Status.Phase = corev1.PodRunning
+	matcher.Expect(r.Status().Update(ctx, &pod)).To(gomega.Succeed())
+
+	// Progress is not reported yet, operation is checked again after the interval
+	result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "backup", Namespace: "ns"}})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(result.RequeueAfter).To(gomega.Equal(10 * time.Second))
+}
+
+func TestOperationFailsWithoutNativeSidecars(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	dmOperation := makeOperation(3)
+	dmOperation.Spec.ProgressIntervalSeconds = 10
+	r := makeOperationReconciler(t, dmOperation, makeOperationSession(api.ProgressReady))
+	// API server without native sidecars drops the container restart policy
+	r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+		Create: func(ctx context.Context, cli client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				for i := range pod.Spec.InitContainers {
+					pod.Spec.InitContainers[i].RestartPolicy = nil
+				}
+			}
+			return cli.Create(ctx, obj, opts...)
+		},
+	})
+
+	result := reconcileOperation(t, r)
+	matcher.Expect(result.Status.Phase).To(gomega.Equal(api.OperationPhaseFailed))
+	matcher.Expect(result.Status.Message).To(gomega.ContainSubstring("native sidecar"))
+	matcher.Expect(apierrors.IsNotFound(r.Get(ctx, types.NamespacedName{Name: "backup-1", Namespace: "ns"}, &corev1.Pod{}))).To(gomega.BeTrue())
+}
+
+func TestOperationHoldsSharedSessionLease(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	dmSession := makeOperationSession(api.ProgressReady)
+	dmSession.Spec.Sharing = &api.SharingConfig{IdleTimeoutSeconds: 60}
+	r := makeOperationReconciler(t, makeOperation(3), dmSession)
+	kubeCli := kubefake.NewSimpleClientset()
+	// Fake client does not generate names
+	kubeCli.PrependReactor("create", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lease := action.(k8stesting.CreateAction).GetObject().(*coordinationv1.Lease)
+		lease.Name = lease.GenerateName + "1"
+		return false, nil, nil
+	})
+	r.KubeClient = kubeCli
+
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseRunning))
+	matcher.Expect(dmOperation.Status.LeaseName).To(gomega.Equal("session-lease-1"))
+	lease, err := kubeCli.CoordinationV1().Leases("ns").Get(ctx, "session-lease-1", metav1.GetOptions{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(*lease.Spec.HolderIdentity).To(gomega.Equal("datamoveroperation/backup"))
+
+	// Lease is renewed while the pod is running
+	pod := corev1.Pod{}
+	matcher.Expect(r.Get(ctx, types.NamespacedName{Name: "backup-1", Namespace: "ns"}, &pod)).To(gomega.Succeed())
+	pod.Status.Phase = corev1.PodRunning
+	matcher.Expect(r.Status().Update(ctx, &pod)).To(gomega.Succeed())
+	result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "backup", Namespace: "ns"}})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(result.RequeueAfter).To(gomega.BeNumerically("~", operationLeaseRenewInterval, time.Second))
+
+	// Lease is released once the operation is finished
+	finishPod(t, r, "backup-1", corev1.PodSucceeded, 0, `{"snapshotID":"snapshot-id"}`)
+	dmOperation = reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseSucceeded))
+	dmOperation = reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.LeaseName).To(gomega.BeEmpty())
+	_, err = kubeCli.CoordinationV1().Leases("ns").Get(ctx, "session-lease-1", metav1.GetOptions{})
+	matcher.Expect(apierrors.IsNotFound(err)).To(gomega.BeTrue())
+}
+
+func TestOperationLeaseResumesIdleSession(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	dmSession := makeOperationSession(api.ProgressIdle)
+	dmSession.Spec.Sharing = &api.SharingConfig{IdleTimeoutSeconds: 60}
+	r := makeOperationReconciler(t, makeOperation(3), dmSession)
+
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhasePending))
+	leases, err := r.KubeClient.CoordinationV1().Leases("ns").List(ctx, metav1.ListOptions{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(leases.Items).To(gomega.HaveLen(1))
+}
+
+func TestOperationReleasesLeaseOnDeletion(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	dmSession := makeOperationSession(api.ProgressReady)
+	dmSession.Spec.Sharing = &api.SharingConfig{IdleTimeoutSeconds: 60}
+	r := makeOperationReconciler(t, makeOperation(3), dmSession)
+	kubeCli := kubefake.NewSimpleClientset()
+	kubeCli.PrependReactor("create", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lease := action.(k8stesting.CreateAction).GetObject().(*coordinationv1.Lease)
+		lease.Name = lease.GenerateName + "1"
+		return false, nil, nil
+	})
+	r.KubeClient = kubeCli
+
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.LeaseName).ToNot(gomega.BeEmpty())
+	matcher.Expect(dmOperation.Finalizers).To(gomega.ContainElement(api.DatamoverOperationLeaseFinalizer))
+
+	// Finalizer keeps the operation until the lease is released
+	matcher.Expect(r.Delete(ctx, &dmOperation)).To(gomega.Succeed())
+	name := types.NamespacedName{Name: "backup", Namespace: "ns"}
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	leases, err := r.KubeClient.CoordinationV1().Leases("ns").List(ctx, metav1.ListOptions{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(leases.Items).To(gomega.BeEmpty())
+	matcher.Expect(apierrors.IsNotFound(r.Get(ctx, name, &api.DatamoverOperation{}))).To(gomega.BeTrue())
+}
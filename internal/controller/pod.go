@@ -11,11 +11,14 @@ import (
 	"github.com/kanisterio/datamover/pkg/session"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const clientSecretVolumeName = "client-creds"
+
 func (r *DatamoverSessionReconciler) CreatePod(ctx context.Context, dmSession api.DatamoverSession) error {
 	podSpec, err := MakePodSpec(dmSession)
 	if err != nil {
@@ -189,10 +192,30 @@ func configMapVolume(ref *corev1.ConfigMapVolumeSource) ([]corev1.Volume, []core
 	return volumes, volumeMounts
 }
 
+// podOutdated checks if the pod was created from the old version of mutable spec fields
+// Currently only client secret can be changed
+func podOutdated(pod corev1.Pod, dmSession api.DatamoverSession) bool {
+	var podClientSecret *corev1.SecretVolumeSource
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == clientSecretVolumeName {
+			podClientSecret = volume.Secret
+		}
+	}
+	specClientSecret := dmSession.Spec.ClientSecretRef
+	if podClientSecret == nil || specClientSecret == nil {
+		return (podClientSecret == nil) != (specClientSecret == nil)
+	}
+	// API server sets defaults for the volume source
+	return podClientSecret.SecretName != specClientSecret.SecretName ||
+		!equality.Semantic.DeepEqual(podClientSecret.Items, specClientSecret.Items) ||
+		!equality.Semantic.DeepEqual(podClientSecret.Optional, specClientSecret.Optional) ||
+		(specClientSecret.DefaultMode != nil && !equality.Semantic.DeepEqual(podClientSecret.DefaultMode, specClientSecret.DefaultMode))
+}
+
 func clientSecretVolume(ref *corev1.SecretVolumeSource) ([]corev1.Volume, []corev1.VolumeMount) {
 	volumes := []corev1.Volume{}
 	volumeMounts := []corev1.VolumeMount{}
-	name := "client-creds"
+	name := clientSecretVolumeName
 	mountPoint := "/etc/client_credentials"
 	if ref != nil {
 		volumes = append(volumes, getSecretVolume(name, *ref))
@@ -459,6 +459,43 @@ func TestMakePodSpecPodOptionsPodOverride(t *testing.T) {
 	matcher.Expect(pod.Spec.ImagePullSecrets).To(gomega.ContainElement(corev1.LocalObjectReference{Name: "image_pull_secret"}))
 }
 
+func TestPodOutdatedOnClientSecretChange(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	dmSession := api.DatamoverSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo_datamover",
+		},
+		Spec: api.DatamoverSessionSpec{
+			Implementation: "foo_impl",
+			ClientSecretRef: &corev1.SecretVolumeSource{
+				SecretName: "client-secret",
+			},
+			LifecycleConfig: &api.LifecycleConfig{
+				Image: "foo_image",
+			},
+		},
+	}
+	pod, err := MakePodSpec(dmSession)
+	matcher.Expect(err).To(gomega.BeNil())
+
+	// Defaults set by API server do not make the pod outdated
+	defaultMode := int32(0644)
+	for i, volume := range pod.Spec.Volumes {
+		if volume.Name == clientSecretVolumeName {
+			pod.Spec.Volumes[i].Secret.DefaultMode = &defaultMode
+		}
+	}
+	matcher.Expect(podOutdated(*pod, dmSession)).To(gomega.BeFalse())
+
+	dmSession.Spec.ClientSecretRef = &corev1.SecretVolumeSource{
+		SecretName: "rotated-client-secret",
+	}
+	matcher.Expect(podOutdated(*pod, dmSession)).To(gomega.BeTrue())
+
+	dmSession.Spec.ClientSecretRef = nil
+	matcher.Expect(podOutdated(*pod, dmSession)).To(gomega.BeTrue())
+}
+
 func assertExtraContainer(t *testing.T, pod corev1.Pod, extraContainer corev1.Container) {
 	matcher := gomega.NewWithT(t)
 	for _, container := range pod.Spec.Containers {
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	dmclient "github.com/kanisterio/datamover/pkg/client"
+)
+
+const gibibyte = int64(1) << 30
+
+// backupPendingError is returned when the backup operation to size the PVC from has not finished yet
+type backupPendingError struct {
+	name string
+}
+
+func (e backupPendingError) Error() string {
+	return fmt.Sprintf("Waiting for backup operation %s to finish", e.name)
+}
+
+// provisionRestorePVC creates the PVC to restore to from pvcTemplate.
+// Returns a message to fail the operation with if the PVC cannot be provisioned,
+// or backupPendingError if the backup operation has not finished yet.
+// PVC is not owned by the operation, so restored data outlives it.
+func (r *DatamoverOperationReconciler) provisionRestorePVC(ctx context.Context, dmOperation *api.DatamoverOperation) (string, error) {
+	name := dmclient.RestorePVCName(*dmOperation)
+
+	existing := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: dmOperation.Namespace}, existing)
+	if err == nil {
+		// Created by the previous attempt
+		if existing.Labels[api.DatamoverOperationLabel] == dmOperation.Name {
+			return "", nil
+		}
+		return fmt.Sprintf("PVC %s already exists", name), nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", errors.Wrap(err, "Unable to get restore PVC")
+	}
+
+	template := dmOperation.Spec.FsRestore.PVCTemplate
+	size := resource.Quantity{}
+	if template.Size != nil {
+		size = *template.Size
+	}
+	if template.BackupOperationName != "" {
+		backupSize, message, err := r.backupSize(ctx, *dmOperation)
+		if message != "" || err != nil {
+			return message, err
+		}
+		if backupSize.Cmp(size) > 0 {
+			size = backupSize
+		}
+	}
+	if size.IsZero() {
+		return "Unable to determine restore PVC size", nil
+	}
+
+	accessModes := template.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	labels := maps.Clone(template.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[api.DatamoverOperationLabel] = dmOperation.Name
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   dmOperation.Namespace,
+			Labels:      labels,
+			Annotations: template.Annotations,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      accessModes,
+			StorageClassName: template.StorageClassName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	if err := r.Create(ctx, pvc); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", errors.Wrap(err, "Unable to create restore PVC")
+	}
+	log.Log.Info("Created restore PVC", "pvc", name, "size", size.String())
+	return "", nil
+}
+
+// backupSize returns size of the backup created by the backup operation
+// with 10% headroom for file system overhead, rounded up to GiB
+func (r *DatamoverOperationReconciler) backupSize(ctx context.Context, dmOperation api.DatamoverOperation) (resource.Quantity, string, error) {
+	backupName := dmOperation.Spec.FsRestore.PVCTemplate.BackupOperationName
+	backup := &api.DatamoverOperation{}
+	err := r.Get(ctx, types.NamespacedName{Name: backupName, Namespace: dmOperation.Namespace}, backup)
+	if apierrors.IsNotFound(err) {
+		return resource.Quantity{}, fmt.Sprintf("Backup operation %s not found", backupName), nil
+	}
+	if err != nil {
+		return resource.Quantity{}, "", errors.Wrap(err, "Unable to get backup operation")
+	}
+	switch backup.Status.Phase {
+	case api.OperationPhaseFailed:
+		return resource.Quantity{}, fmt.Sprintf("Backup operation %s did not succeed", backupName), nil
+	case api.OperationPhaseSucceeded:
+	default:
+		return resource.Quantity{}, "", backupPendingError{name: backupName}
+	}
+	if backup.Status.Result == nil {
+		return resource.Quantity{}, fmt.Sprintf("Backup operation %s has no result", backupName), nil
+	}
+	withHeadroom := backup.Status.Result.SizeBytes + backup.Status.Result.SizeBytes/10
+	gibs := max((withHeadroom+gibibyte-1)/gibibyte, 1)
+	return *resource.NewQuantity(gibs*gibibyte, resource.BinarySI), "", nil
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func makeRestoreOperation(template *api.PVCTemplate) *api.DatamoverOperation {
+	dmOperation := makeOperation(3)
+	dmOperation.Name = "restore"
+	dmOperation.Spec.Type = api.OperationTypeFsRestore
+	dmOperation.Spec.FsBackup = nil
+	dmOperation.Spec.FsRestore = &api.FsRestoreParams{BackupID: "k123", PVCTemplate: template}
+	return dmOperation
+}
+
+func makeSucceededBackup(sizeBytes int64) *api.DatamoverOperation {
+	backup := makeOperation(3)
+	backup.Status = api.DatamoverOperationStatus{
+		Phase:  api.OperationPhaseSucceeded,
+		Result: &api.OperationResult{SnapshotID: "k123", SizeBytes: sizeBytes},
+	}
+	return backup
+}
+
+func reconcileRestore(t *testing.T, r *DatamoverOperationReconciler) api.DatamoverOperation {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	name := types.NamespacedName{Name: "restore", Namespace: "ns"}
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	dmOperation := api.DatamoverOperation{}
+	matcher.Expect(r.Get(ctx, name, &dmOperation)).To(gomega.Succeed())
+	return dmOperation
+}
+
+func TestRestoreProvisionsPVC(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	template := &api.PVCTemplate{
+		StorageClassName:    ptr.To("csi"),
+		Size:                ptr.To(resource.MustParse("1Gi")),
+		BackupOperationName: "backup",
+		Labels:              map[string]string{"app": "db"},
+	}
+	// 2GiB backup is restored to 3GiB PVC
+	r := makeOperationReconciler(t, makeRestoreOperation(template), makeSucceededBackup(2*gibibyte), makeOperationSession(api.ProgressReady))
+
+	dmOperation := reconcileRestore(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseRunning))
+	matcher.Expect(dmOperation.Status.PVCName).To(gomega.Equal("restore-restore"))
+
+	pvc := corev1.PersistentVolumeClaim{}
+	matcher.Expect(r.Get(context.Background(), types.NamespacedName{Name: "restore-restore", Namespace: "ns"}, &pvc)).To(gomega.Succeed())
+	matcher.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(gomega.Equal("3Gi"))
+	matcher.Expect(pvc.Spec.AccessModes).To(gomega.ConsistOf(corev1.ReadWriteOnce))
+	matcher.Expect(*pvc.Spec.StorageClassName).To(gomega.Equal("csi"))
+	matcher.Expect(pvc.Labels).To(gomega.HaveKeyWithValue("app", "db"))
+	matcher.Expect(pvc.OwnerReferences).To(gomega.BeEmpty())
+
+	pod := corev1.Pod{}
+	matcher.Expect(r.Get(context.Background(), types.NamespacedName{Name: "restore-1", Namespace: "ns"}, &pod)).To(gomega.Succeed())
+	matcher.Expect(pod.Spec.Volumes).To(gomega.ContainElement(gomega.HaveField("VolumeSource.PersistentVolumeClaim.ClaimName", "restore-restore")))
+}
+
+func TestRestoreFailsOnExistingPVC(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	dmOperation := makeRestoreOperation(&api.PVCTemplate{Size: ptr.To(resource.MustParse("1Gi"))})
+	dmOperation.Spec.FsRestore.PVC = "data"
+	existing := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "ns"}}
+	r := makeOperationReconciler(t, dmOperation, existing, makeOperationSession(api.ProgressReady))
+
+	result := reconcileRestore(t, r)
+	matcher.Expect(result.Status.Phase).To(gomega.Equal(api.OperationPhaseFailed))
+	matcher.Expect(result.Status.Message).To(gomega.Equal("PVC data already exists"))
+}
+
+func TestRestoreFailsWithoutBackupResult(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	backup := makeOperation(3)
+	backup.Status.Phase = api.OperationPhaseFailed
+	r := makeOperationReconciler(t, makeRestoreOperation(&api.PVCTemplate{BackupOperationName: "backup"}), backup, makeOperationSession(api.ProgressReady))
+
+	dmOperation := reconcileRestore(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseFailed))
+	matcher.Expect(dmOperation.Status.Message).To(gomega.ContainSubstring("did not succeed"))
+}
+
+func TestRestoreWaitsForRunningBackup(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	backup := makeOperation(3)
+	backup.Status.Phase = api.OperationPhaseRunning
+	r := makeOperationReconciler(t, makeRestoreOperation(&api.PVCTemplate{BackupOperationName: "backup"}), backup, makeOperationSession(api.ProgressReady))
+
+	result, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "restore", Namespace: "ns"}})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(result.RequeueAfter).To(gomega.Equal(sessionRecheckInterval))
+
+	dmOperation := reconcileRestore(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhasePending))
+	matcher.Expect(dmOperation.Status.Message).To(gomega.ContainSubstring("Waiting for backup operation backup"))
+
+	// Restore proceeds once the backup succeeds
+	matcher.Expect(r.Get(context.Background(), types.NamespacedName{Name: "backup", Namespace: "ns"}, backup)).To(gomega.Succeed())
+	backup.Status = makeSucceededBackup(gibibyte).Status
+	matcher.Expect(r.Status().Update(context.Background(), backup)).To(gomega.Succeed())
+
+	dmOperation = reconcileRestore(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseRunning))
+}
@@ -2,10 +2,16 @@ package controller
 
 import (
 	"context"
+	"maps"
+
 	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/kanisterio/datamover/pkg/session"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -37,7 +43,7 @@ func (r *DatamoverSessionReconciler) DeleteService(ctx context.Context, service
 
 func makeServiceSpec(dmSession api.DatamoverSession, serviceName string) corev1.Service {
 	ports := dmSession.Spec.LifecycleConfig.ServicePorts
-	return corev1.Service{
+	svc := corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      serviceName,
 			Namespace: dmSession.Namespace,
@@ -53,6 +59,87 @@ func makeServiceSpec(dmSession api.DatamoverSession, serviceName string) corev1.
 			Selector: map[string]string{api.DatamoverSessionSelectorLabel: dmSession.Name},
 		},
 	}
+	config := dmSession.Spec.LifecycleConfig.Service
+	if config == nil {
+		return svc
+	}
+	svc.Annotations = maps.Clone(config.Annotations)
+	svc.Spec.IPFamilies = config.IPFamilies
+	svc.Spec.IPFamilyPolicy = config.IPFamilyPolicy
+	switch config.Type {
+	case api.ServiceTypeHeadless:
+		svc.Spec.Type = corev1.ServiceTypeClusterIP
+		svc.Spec.ClusterIP = corev1.ClusterIPNone
+		// Headless service DNS should resolve before the pod is ready,
+		// session readiness is controlled by the readiness probe
+		svc.Spec.PublishNotReadyAddresses = true
+	case api.ServiceTypeNodePort:
+		svc.Spec.Type = corev1.ServiceTypeNodePort
+	case api.ServiceTypeLoadBalancer:
+		svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+	}
+	return svc
+}
+
+// makeSessionEndpoints lists all endpoints clients can use to connect to the service.
+// Load balancer endpoints are only reported after the load balancer is provisioned.
+func makeSessionEndpoints(dmSession api.DatamoverSession, service *corev1.Service) []api.SessionEndpoint {
+	if service == nil {
+		return nil
+	}
+	hostname := session.ServiceHostname(*service, session.ClusterDomain(dmSession))
+	clusterIPs := []string{}
+	for _, ip := range service.Spec.ClusterIPs {
+		if ip != "" && ip != corev1.ClusterIPNone {
+			clusterIPs = append(clusterIPs, ip)
+		}
+	}
+	if len(clusterIPs) == 0 && service.Spec.ClusterIP != "" && service.Spec.ClusterIP != corev1.ClusterIPNone {
+		clusterIPs = append(clusterIPs, service.Spec.ClusterIP)
+	}
+
+	endpoints := []api.SessionEndpoint{}
+	for _, port := range service.Spec.Ports {
+		endpoints = append(endpoints, api.SessionEndpoint{
+			PortName: port.Name,
+			Type:     api.EndpointTypeDNS,
+			Host:     hostname,
+			Port:     port.Port,
+		})
+		for _, ip := range clusterIPs {
+			endpoints = append(endpoints, api.SessionEndpoint{
+				PortName: port.Name,
+				Type:     api.EndpointTypeClusterIP,
+				Host:     ip,
+				Port:     port.Port,
+			})
+		}
+		if port.NodePort != 0 {
+			endpoints = append(endpoints, api.SessionEndpoint{
+				PortName: port.Name,
+				Type:     api.EndpointTypeNodePort,
+				Port:     port.NodePort,
+			})
+		}
+		if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			for _, ingress := range service.Status.LoadBalancer.Ingress {
+				host := ingress.IP
+				if host == "" {
+					host = ingress.Hostname
+				}
+				if host == "" {
+					continue
+				}
+				endpoints = append(endpoints, api.SessionEndpoint{
+					PortName: port.Name,
+					Type:     api.EndpointTypeLoadBalancer,
+					Host:     host,
+					Port:     port.Port,
+				})
+			}
+		}
+	}
+	return endpoints
 }
 
 func GetServiceName(dmSession api.DatamoverSession) string {
@@ -62,3 +149,42 @@ func GetServiceName(dmSession api.DatamoverSession) string {
 	// but the generated name instead.
 	return dmSession.Name + "-service"
 }
+
+// endpointsOutdated checks if session status endpoints do not match the service
+func endpointsOutdated(dmSession api.DatamoverSession, service *corev1.Service) bool {
+	endpoints := makeSessionEndpoints(dmSession, service)
+	current := dmSession.Status.SessionInfo.Endpoints
+	if len(endpoints) == 0 && len(current) == 0 {
+		return false
+	}
+	return !equality.Semantic.DeepEqual(endpoints, current)
+}
+
+func (r *DatamoverSessionReconciler) getServiceReadiness(ctx context.Context, service corev1.Service, pod *corev1.Pod) (bool, error) {
+	if pod == nil {
+		return false, nil
+	}
+	sliceList := &discoveryv1.EndpointSliceList{}
+	err := r.List(ctx, sliceList, client.InNamespace(service.Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: service.Name})
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to list service endpoint slices")
+	}
+	return serviceReady(sliceList.Items, *pod), nil
+}
+
+// serviceReady checks that the service has a ready endpoint pointing to the session pod,
+// so clients connecting to the service reach the current pod
+func serviceReady(slices []discoveryv1.EndpointSlice, pod corev1.Pod) bool {
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.TargetRef == nil || endpoint.TargetRef.UID != pod.UID {
+				continue
+			}
+			// Nil ready condition should be interpreted as ready
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return true
+			}
+		}
+	}
+	return false
+}
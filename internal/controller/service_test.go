@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"testing"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func makeServiceSession(config *api.ServiceConfig) api.DatamoverSession {
+	return api.DatamoverSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_datamover",
+			Namespace: "foo_namespace",
+		},
+		Spec: api.DatamoverSessionSpec{
+			LifecycleConfig: &api.LifecycleConfig{
+				ServicePorts: []corev1.ServicePort{{Name: "foo", Port: 2000}},
+				Service:      config,
+			},
+		},
+	}
+}
+
+func TestServiceSpecDefaultClusterIP(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	svc := makeServiceSpec(makeServiceSession(nil), "foo-service")
+	matcher.Expect(svc.Spec.Type).To(gomega.BeEmpty())
+	matcher.Expect(svc.Spec.ClusterIP).To(gomega.BeEmpty())
+	matcher.Expect(svc.Annotations).To(gomega.BeEmpty())
+}
+
+func TestServiceSpecHeadless(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	svc := makeServiceSpec(makeServiceSession(&api.ServiceConfig{Type: api.ServiceTypeHeadless}), "foo-service")
+	matcher.Expect(svc.Spec.Type).To(gomega.Equal(corev1.ServiceTypeClusterIP))
+	matcher.Expect(svc.Spec.ClusterIP).To(gomega.Equal(corev1.ClusterIPNone))
+}
+
+func TestServiceSpecLoadBalancer(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	policy := corev1.IPFamilyPolicyPreferDualStack
+	config := &api.ServiceConfig{
+		Type:           api.ServiceTypeLoadBalancer,
+		Annotations:    map[string]string{"lb": "internal"},
+		IPFamilies:     []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+		IPFamilyPolicy: &policy,
+	}
+	svc := makeServiceSpec(makeServiceSession(config), "foo-service")
+	matcher.Expect(svc.Spec.Type).To(gomega.Equal(corev1.ServiceTypeLoadBalancer))
+	matcher.Expect(svc.Annotations).To(gomega.Equal(map[string]string{"lb": "internal"}))
+	matcher.Expect(svc.Spec.IPFamilies).To(gomega.Equal(config.IPFamilies))
+	matcher.Expect(*svc.Spec.IPFamilyPolicy).To(gomega.Equal(policy))
+}
+
+func TestSessionEndpoints(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	dmSession := makeServiceSession(&api.ServiceConfig{
+		Type:          api.ServiceTypeLoadBalancer,
+		ClusterDomain: "example.org",
+	})
+	svc := makeServiceSpec(dmSession, "foo-service")
+	svc.Spec.ClusterIPs = []string{"10.0.0.1", "fd00::1"}
+	svc.Spec.Ports[0].NodePort = 30000
+
+	endpoints := makeSessionEndpoints(dmSession, &svc)
+	matcher.Expect(endpoints).To(gomega.Equal([]api.SessionEndpoint{
+		{PortName: "foo", Type: api.EndpointTypeDNS, Host: "foo-service.foo_namespace.svc.example.org", Port: 2000},
+		{PortName: "foo", Type: api.EndpointTypeClusterIP, Host: "10.0.0.1", Port: 2000},
+		{PortName: "foo", Type: api.EndpointTypeClusterIP, Host: "fd00::1", Port: 2000},
+		{PortName: "foo", Type: api.EndpointTypeNodePort, Port: 30000},
+	}))
+	dmSession.Status.SessionInfo.Endpoints = endpoints
+	matcher.Expect(endpointsOutdated(dmSession, &svc)).To(gomega.BeFalse())
+
+	// Load balancer is provisioned
+	svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.example.org"}}
+	matcher.Expect(endpointsOutdated(dmSession, &svc)).To(gomega.BeTrue())
+	endpoints = makeSessionEndpoints(dmSession, &svc)
+	matcher.Expect(endpoints).To(gomega.ContainElement(api.SessionEndpoint{
+		PortName: "foo", Type: api.EndpointTypeLoadBalancer, Host: "lb.example.org", Port: 2000,
+	}))
+}
+
+func TestSessionEndpointsHeadless(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	dmSession := makeServiceSession(&api.ServiceConfig{Type: api.ServiceTypeHeadless})
+	svc := makeServiceSpec(dmSession, "foo-service")
+	svc.Spec.ClusterIPs = []string{corev1.ClusterIPNone}
+
+	endpoints := makeSessionEndpoints(dmSession, &svc)
+	matcher.Expect(endpoints).To(gomega.Equal([]api.SessionEndpoint{
+		{PortName: "foo", Type: api.EndpointTypeDNS, Host: "foo-service.foo_namespace.svc.cluster.local", Port: 2000},
+	}))
+}
+
+func TestServiceReadyForCurrentPod(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "pod-uid"}}
+	notReady := false
+	slices := []discoveryv1.EndpointSlice{{
+		Endpoints: []discoveryv1.Endpoint{
+			// Previous pod
+			{TargetRef: &corev1.ObjectReference{UID: "old-pod-uid"}},
+			{TargetRef: &corev1.ObjectReference{UID: "pod-uid"}, Conditions: discoveryv1.EndpointConditions{Ready: &notReady}},
+		},
+	}}
+	matcher.Expect(serviceReady(slices, pod)).To(gomega.BeFalse())
+
+	ready := true
+	slices[0].Endpoints[1].Conditions.Ready = &ready
+	matcher.Expect(serviceReady(slices, pod)).To(gomega.BeTrue())
+}
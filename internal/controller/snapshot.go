@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	dmclient "github.com/kanisterio/datamover/pkg/client"
+)
+
+// VolumeSnapshot is used as unstructured to not depend on the external snapshotter client
+var volumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1",
+	Kind:    "VolumeSnapshot",
+}
+
+// snapshotFailedError is returned when the volume snapshot reports an error
+type snapshotFailedError struct {
+	message string
+}
+
+func (e snapshotFailedError) Error() string {
+	return "Volume snapshot failed: " + e.message
+}
+
+// prepareSnapshot creates a VolumeSnapshot of the source PVC and a temporary PVC from it.
+// Returns a message to wait with if the snapshot is not ready yet,
+// or snapshotFailedError if the snapshot reports an error.
+func (r *DatamoverOperationReconciler) prepareSnapshot(ctx context.Context, dmOperation *api.DatamoverOperation) (string, error) {
+	params := dmOperation.Spec.SnapshotBackup
+	name := dmclient.SnapshotBackupPVCName(*dmOperation)
+	key := types.NamespacedName{Name: name, Namespace: dmOperation.Namespace}
+
+	sourcePVC := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: params.PVC, Namespace: dmOperation.Namespace}, sourcePVC); err != nil {
+		return "", errors.Wrap(err, "Unable to get snapshot source PVC")
+	}
+
+	snapshot := newVolumeSnapshot()
+	err := r.Get(ctx, key, snapshot)
+	if apierrors.IsNotFound(err) {
+		snapshot, err = r.makeVolumeSnapshot(*dmOperation, name)
+		if err != nil {
+			return "", err
+		}
+		if err := r.Create(ctx, snapshot); err != nil && !apierrors.IsAlreadyExists(err) {
+			return "", errors.Wrap(err, "Unable to create volume snapshot")
+		}
+		return "Waiting for volume snapshot to be ready", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to get volume snapshot")
+	}
+
+	// Failed snapshot of the previous attempt
+	if snapshot.GetDeletionTimestamp() != nil {
+		return "Waiting for failed volume snapshot to be deleted", nil
+	}
+
+	ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	if !ready {
+		if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+			return "", snapshotFailedError{message: message}
+		}
+		return "Waiting for volume snapshot to be ready", nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err = r.Get(ctx, key, pvc)
+	if apierrors.IsNotFound(err) {
+		pvc, err = r.makeSnapshotPVC(*dmOperation, name, *sourcePVC, snapshot)
+		if err != nil {
+			return "", err
+		}
+		if err := r.Create(ctx, pvc); err != nil && !apierrors.IsAlreadyExists(err) {
+			return "", errors.Wrap(err, "Unable to create PVC from volume snapshot")
+		}
+		log.Log.Info("Created PVC from volume snapshot", "pvc", name)
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to get PVC from volume snapshot")
+	}
+	// PVC may be pending until the client pod is scheduled with WaitForFirstConsumer binding
+	return "", nil
+}
+
+func (r *DatamoverOperationReconciler) makeVolumeSnapshot(dmOperation api.DatamoverOperation, name string) (*unstructured.Unstructured, error) {
+	params := dmOperation.Spec.SnapshotBackup
+	snapshot := newVolumeSnapshot()
+	snapshot.SetName(name)
+	snapshot.SetNamespace(dmOperation.Namespace)
+	snapshot.SetLabels(map[string]string{api.DatamoverOperationLabel: dmOperation.Name})
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": params.PVC,
+		},
+	}
+	if params.VolumeSnapshotClassName != nil {
+		spec["volumeSnapshotClassName"] = *params.VolumeSnapshotClassName
+	}
+	snapshot.Object["spec"] = spec
+	if err := ctrl.SetControllerReference(&dmOperation, snapshot, r.Scheme); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// makeSnapshotPVC creates PVC from the snapshot matching the source PVC
+// Size is taken from the snapshot restoreSize if it's reported
+func (r *DatamoverOperationReconciler) makeSnapshotPVC(
+	dmOperation api.DatamoverOperation,
+	name string,
+	sourcePVC corev1.PersistentVolumeClaim,
+	snapshot *unstructured.Unstructured,
+) (*corev1.PersistentVolumeClaim, error) {
+	storageClassName := sourcePVC.Spec.StorageClassName
+	if dmOperation.Spec.SnapshotBackup.StorageClassName != nil {
+		storageClassName = dmOperation.Spec.SnapshotBackup.StorageClassName
+	}
+	resources := sourcePVC.Spec.Resources
+	if restoreSize, found, _ := unstructured.NestedString(snapshot.Object, "status", "restoreSize"); found {
+		size, err := resource.ParseQuantity(restoreSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid volume snapshot restore size")
+		}
+		if size.Cmp(resources.Requests[corev1.ResourceStorage]) > 0 {
+			resources = corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			}
+		}
+	}
+	apiGroup := volumeSnapshotGVK.Group
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: dmOperation.Namespace,
+			Labels:    map[string]string{api.DatamoverOperationLabel: dmOperation.Name},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      sourcePVC.Spec.AccessModes,
+			VolumeMode:       sourcePVC.Spec.VolumeMode,
+			StorageClassName: storageClassName,
+			Resources:        resources,
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     volumeSnapshotGVK.Kind,
+				Name:     snapshot.GetName(),
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(&dmOperation, pvc, r.Scheme); err != nil {
+		return nil, err
+	}
+	return pvc, nil
+}
+
+// retrySnapshot deletes the failed volume snapshot, so a new one is created by the next attempt.
+// Snapshot failures count against the backoff limit.
+func (r *DatamoverOperationReconciler) retrySnapshot(ctx context.Context, dmOperation *api.DatamoverOperation, message string) error {
+	if err := r.cleanupSnapshot(ctx, *dmOperation); err != nil {
+		return err
+	}
+	dmOperation.Status.Attempts++
+	return r.retryOrFail(ctx, dmOperation, nil, message)
+}
+
+// cleanupSnapshot deletes the volume snapshot and the temporary PVC of the finished operation
+func (r *DatamoverOperationReconciler) cleanupSnapshot(ctx context.Context, dmOperation api.DatamoverOperation) error {
+	name := dmclient.SnapshotBackupPVCName(dmOperation)
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: dmOperation.Namespace}}
+	if err := r.Delete(ctx, pvc); client.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, "Unable to delete PVC from volume snapshot")
+	}
+	snapshot := newVolumeSnapshot()
+	snapshot.SetName(name)
+	snapshot.SetNamespace(dmOperation.Namespace)
+	if err := r.Delete(ctx, snapshot); client.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, "Unable to delete volume snapshot")
+	}
+	return nil
+}
+
+func newVolumeSnapshot() *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	return snapshot
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func makeSnapshotOperation() *api.DatamoverOperation {
+	dmOperation := makeOperation(3)
+	dmOperation.Spec.Type = api.OperationTypeSnapshotBackup
+	dmOperation.Spec.FsBackup = nil
+	dmOperation.Spec.SnapshotBackup = &api.SnapshotBackupParams{
+		PVC:                     "data",
+		Path:                    "/",
+		VolumeSnapshotClassName: ptr.To("csi-snapclass"),
+	}
+	return dmOperation
+}
+
+func makeSourcePVC() *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "ns"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: ptr.To("csi"),
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+}
+
+func TestSnapshotBackupOperation(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	r := makeOperationReconciler(t, makeSnapshotOperation(), makeOperationSession(api.ProgressReady), makeSourcePVC())
+	key := types.NamespacedName{Name: "backup-snapshot", Namespace: "ns"}
+
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhasePending))
+	matcher.Expect(dmOperation.Status.Message).To(gomega.ContainSubstring("volume snapshot"))
+
+	snapshot := newVolumeSnapshot()
+	matcher.Expect(r.Get(ctx, key, snapshot)).To(gomega.Succeed())
+	source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	matcher.Expect(source).To(gomega.Equal("data"))
+	class, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	matcher.Expect(class).To(gomega.Equal("csi-snapclass"))
+
+	matcher.Expect(unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse")).To(gomega.Succeed())
+	matcher.Expect(unstructured.SetNestedField(snapshot.Object, "2Gi", "status", "restoreSize")).To(gomega.Succeed())
+	matcher.Expect(r.Update(ctx, snapshot)).To(gomega.Succeed())
+
+	dmOperation = reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseRunning))
+
+	pvc := corev1.PersistentVolumeClaim{}
+	matcher.Expect(r.Get(ctx, key, &pvc)).To(gomega.Succeed())
+	matcher.Expect(pvc.Spec.DataSource.Name).To(gomega.Equal("backup-snapshot"))
+	matcher.Expect(*pvc.Spec.StorageClassName).To(gomega.Equal("csi"))
+	matcher.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(gomega.Equal("2Gi"))
+
+	pod := corev1.Pod{}
+	matcher.Expect(r.Get(ctx, types.NamespacedName{Name: "backup-1", Namespace: "ns"}, &pod)).To(gomega.Succeed())
+	matcher.Expect(pod.Spec.Volumes).To(gomega.ContainElement(gomega.HaveField("VolumeSource.PersistentVolumeClaim.ClaimName", "backup-snapshot")))
+
+	finishPod(t, r, "backup-1", corev1.PodSucceeded, 0, `{"snapshotID":"snapshot-id"}`)
+	dmOperation = reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhaseSucceeded))
+
+	// Finished operation cleans up the snapshot and the PVC
+	reconcileOperation(t, r)
+	matcher.Expect(apierrors.IsNotFound(r.Get(ctx, key, &pvc))).To(gomega.BeTrue())
+	matcher.Expect(apierrors.IsNotFound(r.Get(ctx, key, newVolumeSnapshot()))).To(gomega.BeTrue())
+}
+
+func failSnapshot(t *testing.T, r *DatamoverOperationReconciler, key types.NamespacedName) {
+	matcher := gomega.NewWithT(t)
+	snapshot := newVolumeSnapshot()
+	matcher.Expect(r.Get(context.Background(), key, snapshot)).To(gomega.Succeed())
+	matcher.Expect(unstructured.SetNestedField(snapshot.Object, "snapshot timed out", "status", "error", "message")).To(gomega.Succeed())
+	matcher.Expect(r.Update(context.Background(), snapshot)).To(gomega.Succeed())
+}
+
+func TestSnapshotErrorRetries(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	r := makeOperationReconciler(t, makeSnapshotOperation(), makeOperationSession(api.ProgressReady), makeSourcePVC())
+	key := types.NamespacedName{Name: "backup-snapshot", Namespace: "ns"}
+
+	reconcileOperation(t, r)
+	failSnapshot(t, r, key)
+
+	dmOperation := reconcileOperation(t, r)
+	matcher.Expect(dmOperation.Status.Phase).To(gomega.Equal(api.OperationPhasePending))
+	matcher.Expect(dmOperation.Status.Attempts).To(gomega.Equal(int32(1)))
+	matcher.Expect(dmOperation.Status.Message).To(gomega.ContainSubstring("snapshot timed out"))
+	matcher.Expect(apierrors.IsNotFound(r.Get(ctx, key, newVolumeSnapshot()))).To(gomega.BeTrue())
+
+	// Next attempt creates a new snapshot
+	reconcileOperation(t, r)
+	matcher.Expect(r.Get(ctx, key, newVolumeSnapshot())).To(gomega.Succeed())
+}
+
+func TestSnapshotErrorBackoffLimit(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	dmOperation := makeSnapshotOperation()
+	dmOperation.Spec.BackoffLimit = ptr.To(int32(0))
+	r := makeOperationReconciler(t, dmOperation, makeOperationSession(api.ProgressReady), makeSourcePVC())
+
+	reconcileOperation(t, r)
+	failSnapshot(t, r, types.NamespacedName{Name: "backup-snapshot", Namespace: "ns"})
+
+	result := reconcileOperation(t, r)
+	matcher.Expect(result.Status.Phase).To(gomega.Equal(api.OperationPhaseFailed))
+	matcher.Expect(result.Status.Message).To(gomega.ContainSubstring("snapshot timed out"))
+}
@@ -3,11 +3,13 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	api "github.com/kanisterio/datamover/api/v1alpha1"
 	"github.com/kanisterio/datamover/pkg/session"
 	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -16,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -26,6 +29,7 @@ const (
 	Init
 
 	ValidationFailed
+	Queued
 	CreateResourcesSuccess
 	// TODO: these states are reachable only if we introduce a retry limit
 	// CreateResourcesFailedDirty
@@ -45,6 +49,33 @@ const (
 	SessionFailedDirty
 	SessionFailedClean
 
+	SuspendInProgress
+	SuspendPodDeleted
+	Suspended
+	Resuming
+
+	// Mutable spec fields changed and resources need to be updated
+	PodOutdated
+	NetworkPolicyOutdated
+	RestartInProgress
+
+	// Service endpoints changed, e.g. load balancer was provisioned
+	EndpointsOutdated
+
+	// Shared session lease count changed
+	LeasesOutdated
+	// Shared session had no active leases for longer than the idle timeout
+	SessionIdle
+	// Deleting resources of an idle shared session
+	IdleInProgress
+	Idle
+
+	// Shared session does not have the lease finalizer yet
+	LeaseFinalizerMissing
+	// Shared session is deleted, but still has active leases
+	DeletionWaitForLeases
+	DeletionLeasesReleased
+
 	// These states are outside of reconcile loop
 	// Empty
 	// EmptyTerminating
@@ -78,6 +109,13 @@ func (r *DatamoverSessionReconciler) Run(ctx context.Context, dmSession *api.Dat
 			// Shortcut for terminal state, do not requeue
 			return ctrl.Result{}, nil
 		}
+		admitted, err := r.admitOrQueue(ctx, dmSession)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !admitted {
+			return requeue_wait_sec(queuedRecheckInterval), nil
+		}
 		err = r.tryCreateResources(ctx, *dmSession, resources)
 		if err != nil {
 			return ctrl.Result{}, err
@@ -87,7 +125,31 @@ func (r *DatamoverSessionReconciler) Run(ctx context.Context, dmSession *api.Dat
 		return requeue_wait_sec(20), nil
 	case ValidationFailed:
 		return ctrl.Result{}, nil
+	case Queued:
+		admitted, err := r.admit(ctx, *dmSession)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !admitted {
+			return requeue_wait_sec(queuedRecheckInterval), nil
+		}
+		log.Log.Info("Session admitted from the queue")
+		err = r.tryCreateResources(ctx, *dmSession, resources)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return requeue_wait_sec(20), nil
 	case CreateResourcesInProgress:
+		// Pod creation requires admission, e.g. when resuming a session with existing service
+		if resources.pod == nil {
+			admitted, err := r.admitOrQueue(ctx, dmSession)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if !admitted {
+				return requeue_wait_sec(queuedRecheckInterval), nil
+			}
+		}
 		err := r.tryCreateResources(ctx, *dmSession, resources)
 		if err != nil {
 			return ctrl.Result{}, err
@@ -142,6 +204,9 @@ func (r *DatamoverSessionReconciler) Run(ctx context.Context, dmSession *api.Dat
 		return ctrl.Result{}, nil
 
 	case SessionRunning:
+		if after, ok := nextLeaseCheck(*dmSession, resources.leases, time.Now()); ok {
+			return ctrl.Result{RequeueAfter: after}, nil
+		}
 		return ctrl.Result{}, nil
 
 	case SessionResourcesFailure:
@@ -161,6 +226,105 @@ func (r *DatamoverSessionReconciler) Run(ctx context.Context, dmSession *api.Dat
 	case SessionFailedClean:
 		return ctrl.Result{}, nil
 
+	case SuspendInProgress, RestartInProgress:
+		log.Log.Info("Deleting session pod", "state", state)
+		err := r.CleanupPod(ctx, dmSession, resources)
+		if err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		// Pod deletion should trigger further reconcile
+		return requeue_wait_sec(20), nil
+
+	case SuspendPodDeleted:
+		err := r.UpdateStatus(ctx, dmSession, api.ProgressSuspended)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+
+	case Suspended:
+		if after, ok := nextLeaseCheck(*dmSession, resources.leases, time.Now()); ok {
+			return ctrl.Result{RequeueAfter: after}, nil
+		}
+		return ctrl.Result{}, nil
+
+	case Resuming:
+		err := r.UpdateStatusResume(ctx, dmSession)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+
+	case PodOutdated:
+		log.Log.Info("Session pod does not match the spec, restarting")
+		err := r.UpdateStatus(ctx, dmSession, api.ProgressRestarting)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+
+	case NetworkPolicyOutdated:
+		err := r.UpdateNetworkPolicy(ctx, *dmSession, resources.networkPolicy)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+
+	case LeasesOutdated:
+		err := r.UpdateStatusLeases(ctx, dmSession, *resources)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+
+	case SessionIdle:
+		log.Log.Info("Shared session has no active leases, deleting session resources")
+		err := r.UpdateStatus(ctx, dmSession, api.ProgressIdle)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+
+	case IdleInProgress:
+		err := r.CleanupPod(ctx, dmSession, resources)
+		if err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		err = r.CleanupService(ctx, dmSession, resources)
+		if err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		// Resources deletion should trigger further reconcile
+		return requeue_wait_sec(20), nil
+
+	case Idle:
+		// Lease creation triggers reconcile to resume the session
+		return ctrl.Result{}, nil
+
+	case LeaseFinalizerMissing:
+		err := r.AddLeaseFinalizer(ctx, dmSession)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+
+	case DeletionWaitForLeases:
+		return r.WaitForLeases(ctx, dmSession, *resources)
+
+	case DeletionLeasesReleased:
+		err := r.RemoveLeaseFinalizer(ctx, dmSession)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+
+	case EndpointsOutdated:
+		err := r.UpdateStatusEndpoints(ctx, dmSession, *resources)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+
 	case None:
 		return ctrl.Result{}, fmt.Errorf("Invalid state. Unknown state None. Should have returned an error from GetState")
 
@@ -169,6 +333,22 @@ func (r *DatamoverSessionReconciler) Run(ctx context.Context, dmSession *api.Dat
 	}
 }
 
+// admitOrQueue sets the session progress to Queued if concurrency limits
+// do not allow to create the session pod
+func (r *DatamoverSessionReconciler) admitOrQueue(ctx context.Context, dmSession *api.DatamoverSession) (bool, error) {
+	admitted, err := r.admit(ctx, *dmSession)
+	if err != nil {
+		return false, err
+	}
+	if !admitted {
+		err := r.UpdateStatus(ctx, dmSession, api.ProgressQueued)
+		if err != nil {
+			return false, err
+		}
+	}
+	return admitted, nil
+}
+
 func (r *DatamoverSessionReconciler) tryCreateResources(ctx context.Context, dmSession api.DatamoverSession, resources *resources) error {
 	err := r.CreateResources(ctx, dmSession, resources)
 
@@ -234,7 +414,7 @@ func (r *DatamoverSessionReconciler) CleanupPod(ctx context.Context, dmSession *
 }
 
 func (r *DatamoverSessionReconciler) UpdateStatus(ctx context.Context, dmSession *api.DatamoverSession, status api.DatamoverSessionProgress) error {
-	dmSession.Status.Progress = status
+	setProgress(dmSession, status)
 	if err := r.Status().Update(ctx, dmSession); err != nil {
 		// TODO: wrap error
 		return err
@@ -243,6 +423,16 @@ func (r *DatamoverSessionReconciler) UpdateStatus(ctx context.Context, dmSession
 	return nil
 }
 
+// setProgress sets the session progress and records the time the session failed,
+// which is used as a start of failed sessions TTL
+func setProgress(dmSession *api.DatamoverSession, status api.DatamoverSessionProgress) {
+	dmSession.Status.Progress = status
+	if slices.Contains(session.FailedProgress, status) && dmSession.Status.FailedTime == nil {
+		now := metav1.Now()
+		dmSession.Status.FailedTime = &now
+	}
+}
+
 func (r *DatamoverSessionReconciler) UpdateStatusResources(ctx context.Context, dmSession *api.DatamoverSession, status api.DatamoverSessionProgress, resources resources) error {
 	dmSession.Status.Progress = status
 	serviceName := ""
@@ -266,7 +456,7 @@ func (r *DatamoverSessionReconciler) UpdateStatusResources(ctx context.Context,
 }
 
 func (r *DatamoverSessionReconciler) UpdateStatusFailure(ctx context.Context, dmSession *api.DatamoverSession, status api.DatamoverSessionProgress, resources *resources) error {
-	dmSession.Status.Progress = status
+	setProgress(dmSession, status)
 	serviceName := dmSession.Status.SessionInfo.ServiceName
 	if resources != nil && resources.service != nil {
 		serviceName = resources.service.Name
@@ -306,6 +496,7 @@ func (r *DatamoverSessionReconciler) UpdateStatusData(ctx context.Context, dmSes
 		PodName:     resources.pod.Name,
 		ServiceName: serviceName,
 		SessionData: resources.podReadiness.data,
+		Endpoints:   makeSessionEndpoints(*dmSession, resources.service),
 	}
 	if err := r.Status().Update(ctx, dmSession); err != nil {
 		// TODO: wrap error
@@ -314,12 +505,50 @@ func (r *DatamoverSessionReconciler) UpdateStatusData(ctx context.Context, dmSes
 	return nil
 }
 
+func (r *DatamoverSessionReconciler) UpdateStatusEndpoints(ctx context.Context, dmSession *api.DatamoverSession, resources resources) error {
+	dmSession.Status.SessionInfo.Endpoints = makeSessionEndpoints(*dmSession, resources.service)
+	if err := r.Status().Update(ctx, dmSession); err != nil {
+		// TODO: wrap error
+		return err
+	}
+	log.Log.Info("Updated session endpoints")
+	return nil
+}
+
+// UpdateStatusResume resets progress so the session goes through resource
+// creation and readiness again. Session data from the previous pod is cleared.
+func (r *DatamoverSessionReconciler) UpdateStatusResume(ctx context.Context, dmSession *api.DatamoverSession) error {
+	dmSession.Status.Progress = api.ProgressNone
+	dmSession.Status.SessionInfo = api.SessionInfo{
+		ServiceName: dmSession.Status.SessionInfo.ServiceName,
+	}
+	if err := r.Status().Update(ctx, dmSession); err != nil {
+		// TODO: wrap error
+		return err
+	}
+	log.Log.Info("Resumed session")
+	return nil
+}
+
 func (r *DatamoverSessionReconciler) GetState(ctx context.Context, dmSession *api.DatamoverSession) (State, *resources, error) {
 	resources, err := r.getResources(ctx, dmSession)
 	if err != nil {
 		return None, nil, errors.Wrap(err, "Error while getting resources")
 	}
 
+	now := time.Now()
+	if state, ok := getFinalizerState(*dmSession, *resources, now); ok {
+		return state, resources, nil
+	}
+
+	if state, ok := getSharingState(*dmSession, *resources, now); ok {
+		return state, resources, nil
+	}
+
+	if state, ok := getSuspendState(*dmSession, *resources); ok {
+		return state, resources, nil
+	}
+
 	switch dmSession.Status.Progress {
 	case api.ProgressNone: // Progress is not set yet
 		if dmSession.Status.SessionInfo.SessionData != "" {
@@ -338,6 +567,16 @@ func (r *DatamoverSessionReconciler) GetState(ctx context.Context, dmSession *ap
 		return CreateResourcesInProgress, resources, nil
 	case api.ProgressValidationFailed:
 		return ValidationFailed, nil, nil
+	case api.ProgressQueued:
+		// Waiting for admission
+		if !podExists(*resources) {
+			return Queued, resources, nil
+		}
+		// Admitted, continue the same way as from no progress
+		if resourcesExist(*resources) {
+			return CreateResourcesSuccess, resources, nil
+		}
+		return CreateResourcesInProgress, resources, nil
 	case api.ProgressResourcesCreated:
 		// Resources missing
 		if !resourcesExist(*resources) {
@@ -348,7 +587,12 @@ func (r *DatamoverSessionReconciler) GetState(ctx context.Context, dmSession *ap
 			return ReadinessResourcesFailure, resources, nil
 		}
 
-		if resourcesReady(*resources) {
+		if state, ok := getOutdatedState(*dmSession, *resources); ok {
+			return state, resources, nil
+		}
+
+		// Session is ready when clients can connect to the service
+		if resourcesReady(*resources) && serviceReadyOk(*resources) {
 			return ReadinessSuccess, resources, nil
 		}
 		return ReadinessWait, resources, nil
@@ -367,7 +611,13 @@ func (r *DatamoverSessionReconciler) GetState(ctx context.Context, dmSession *ap
 		if resourcesFailed(*resources) {
 			return SessionResourcesFailure, resources, nil
 		}
+		if state, ok := getOutdatedState(*dmSession, *resources); ok {
+			return state, resources, nil
+		}
 		if resourcesReady(*resources) {
+			if endpointsOutdated(*dmSession, resources.service) {
+				return EndpointsOutdated, resources, nil
+			}
 			return SessionRunning, resources, nil
 		}
 		// NOTE: this state should not be possible
@@ -377,14 +627,69 @@ func (r *DatamoverSessionReconciler) GetState(ctx context.Context, dmSession *ap
 			return SessionFailedClean, resources, nil
 		}
 		return SessionFailedDirty, resources, nil
+	case api.ProgressIdle:
+		return getIdleState(*dmSession, *resources, now), resources, nil
 	}
 	return None, nil, fmt.Errorf("Invalid state. Unknown state progress: %s", dmSession.Status.Progress)
 }
 
+// getSuspendState returns suspend related states if the session is suspended or resumed
+func getSuspendState(dmSession api.DatamoverSession, resources resources) (State, bool) {
+	progress := dmSession.Status.Progress
+	// Restart deletes the pod and resumes the session
+	if progress == api.ProgressRestarting {
+		if podExists(resources) {
+			return RestartInProgress, true
+		}
+		if dmSession.Spec.Suspend {
+			return SuspendPodDeleted, true
+		}
+		return Resuming, true
+	}
+	if !dmSession.Spec.Suspend {
+		if progress == api.ProgressSuspended {
+			return Resuming, true
+		}
+		return None, false
+	}
+	switch progress {
+	// Failed sessions are not suspended to keep failure information
+	case api.ProgressValidationFailed, api.ProgressReadinessFailure, api.ProgressSessionFailure:
+		return None, false
+	// Idle session has no pod and stays idle until resumed by a lease
+	case api.ProgressIdle:
+		return None, false
+	case api.ProgressSuspended:
+		if podExists(resources) {
+			return SuspendInProgress, true
+		}
+		return Suspended, true
+	}
+	if podExists(resources) {
+		return SuspendInProgress, true
+	}
+	return SuspendPodDeleted, true
+}
+
+// getOutdatedState checks if existing resources need to be updated after spec changes
+func getOutdatedState(dmSession api.DatamoverSession, resources resources) (State, bool) {
+	if resources.pod != nil && podOutdated(*resources.pod, dmSession) {
+		return PodOutdated, true
+	}
+	if resources.networkPolicy != nil && networkPolicyOutdated(*resources.networkPolicy, dmSession) {
+		return NetworkPolicyOutdated, true
+	}
+	return None, false
+}
+
 type resources struct {
-	pod               *corev1.Pod
-	podReadiness      *readiness
-	service           *corev1.Service
+	// Client leases of a shared session
+	leases       []coordinationv1.Lease
+	pod          *corev1.Pod
+	podReadiness *readiness
+	service      *corev1.Service
+	// Service has a ready endpoint for the pod
+	serviceReady      bool
 	needService       bool
 	networkPolicy     *networkingv1.NetworkPolicy
 	needNetworkPolicy bool
@@ -416,6 +721,10 @@ func serviceOk(resources resources) bool {
 	return !resources.needService || resources.service != nil
 }
 
+func serviceReadyOk(resources resources) bool {
+	return !resources.needService || resources.serviceReady
+}
+
 func networkPolicyOk(resources resources) bool {
 	return !resources.needNetworkPolicy || resources.networkPolicy != nil
 }
@@ -477,14 +786,20 @@ func (r *DatamoverSessionReconciler) getResources(ctx context.Context, dmSession
 			return nil, err
 		}
 	}
-	// TODO: service readiness
 	needService := len(dmSession.Spec.LifecycleConfig.ServicePorts) > 0
 	var service *corev1.Service
+	serviceReady := false
 	if needService {
 		service, err = r.getService(ctx, dmSession)
 		if err != nil {
 			return nil, err
 		}
+		if service != nil {
+			serviceReady, err = r.getServiceReadiness(ctx, *service, pod)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	needNetworkPolicy := dmSession.Spec.LifecycleConfig.NetworkPolicy.Enabled
@@ -496,10 +811,21 @@ func (r *DatamoverSessionReconciler) getResources(ctx context.Context, dmSession
 		}
 	}
 
+	var leases []coordinationv1.Lease
+	// Leases are checked after sharing is disabled until the finalizer is removed
+	if dmSession.Spec.Sharing != nil || controllerutil.ContainsFinalizer(dmSession, api.DatamoverSessionLeaseFinalizer) {
+		leases, err = r.getLeases(ctx, dmSession)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &resources{
+		leases:            leases,
 		pod:               pod,
 		podReadiness:      podReadiness,
 		service:           service,
+		serviceReady:      serviceReady,
 		needService:       needService,
 		networkPolicy:     networkPolicy,
 		needNetworkPolicy: needNetworkPolicy,
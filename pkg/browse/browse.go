@@ -0,0 +1,89 @@
+// Package browse serves a backup snapshot over an HTTP API
+// so users can browse it before restoring.
+//
+// API:
+//
+//	GET /healthz                       readiness check
+//	GET /api/v1/list?path=<dir>        lists entries of the directory as ListResponse
+//	GET /api/v1/stat?path=<path>       describes the entry as Entry
+//	GET /api/v1/download?path=<file>   downloads the file content
+//
+// API requests require the token in the Authorization: Bearer <token> header.
+// Paths are relative to the snapshot root, empty path is the root directory.
+// Errors are returned as ErrorResponse with 404 status if the path does not exist,
+// 400 status if the path has a wrong type for the request
+// and 401 status if the token is missing or wrong.
+package browse
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNotFound     = errors.New("Path not found")
+	ErrNotDirectory = errors.New("Path is not a directory")
+	ErrIsDirectory  = errors.New("Path is a directory")
+	ErrUnauthorized = errors.New("Unauthorized")
+)
+
+// EntryType is the type of the snapshot entry
+type EntryType string
+
+const (
+	EntryTypeFile      EntryType = "file"
+	EntryTypeDirectory EntryType = "directory"
+	EntryTypeSymlink   EntryType = "symlink"
+	EntryTypeOther     EntryType = "other"
+)
+
+// Entry describes a file or a directory in the snapshot
+type Entry struct {
+	Name string `json:"name"`
+	// Path relative to the snapshot root
+	Path string    `json:"path"`
+	Type EntryType `json:"type"`
+	// Size of the file or total size of files in the directory
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode,omitempty"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// ListResponse is returned by the list request
+type ListResponse struct {
+	Path    string  `json:"path"`
+	Entries []Entry `json:"entries"`
+}
+
+// ErrorResponse is returned by failed requests
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Snapshot provides read access to a backup snapshot
+// Implementations should return ErrNotFound, ErrNotDirectory and ErrIsDirectory errors
+// to report invalid paths.
+type Snapshot interface {
+	List(ctx context.Context, path string) ([]Entry, error)
+	Stat(ctx context.Context, path string) (*Entry, error)
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// CleanPath converts the requested path to a path relative to the snapshot root,
+// paths cannot escape the root
+func CleanPath(requestPath string) string {
+	return strings.TrimPrefix(path.Clean("/"+requestPath), "/")
+}
+
+// SplitPath returns path components of the clean path
+func SplitPath(cleanPath string) []string {
+	if cleanPath == "" {
+		return nil
+	}
+	return strings.Split(cleanPath, "/")
+}
@@ -0,0 +1,233 @@
+package browse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Directory listings are immutable in the snapshot, cache up to this number of them
+const kopiaListingCacheSize = 1000
+
+// ErrSnapshotNotFound is returned if the snapshot does not exist in the repository
+var ErrSnapshotNotFound = errors.New("Snapshot not found")
+
+// CommandRunner runs kopia CLI command and returns its stdout
+type CommandRunner func(ctx context.Context, args ...string) (io.ReadCloser, error)
+
+// KopiaSnapshot reads the snapshot with kopia CLI
+// Kopia should be connected to the repository.
+type KopiaSnapshot struct {
+	run  CommandRunner
+	root kopiaEntry
+
+	mutex    sync.Mutex
+	listings map[string][]kopiaEntry
+}
+
+var _ Snapshot = &KopiaSnapshot{}
+
+// kopiaEntry is the directory entry of kopia snapshot
+type kopiaEntry struct {
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Mode       string    `json:"mode"`
+	ModTime    time.Time `json:"mtime"`
+	ObjectID   string    `json:"obj"`
+	Size       int64     `json:"size"`
+	DirSummary *struct {
+		TotalFileSize int64 `json:"size"`
+	} `json:"summ"`
+}
+
+type kopiaManifest struct {
+	ID        string     `json:"id"`
+	RootEntry kopiaEntry `json:"rootEntry"`
+}
+
+type kopiaDirectory struct {
+	Entries []kopiaEntry `json:"entries"`
+}
+
+// NewKopiaSnapshot finds the snapshot with manifest ID in the repository
+func NewKopiaSnapshot(ctx context.Context, snapshotID string, run CommandRunner) (*KopiaSnapshot, error) {
+	if run == nil {
+		run = RunKopia
+	}
+	manifests := []kopiaManifest{}
+	if err := runJSON(ctx, run, &manifests, "snapshot", "list", "--all", "--json"); err != nil {
+		return nil, errors.Wrap(err, "Unable to list snapshots")
+	}
+	for _, manifest := range manifests {
+		if manifest.ID == snapshotID {
+			return &KopiaSnapshot{
+				run:      run,
+				root:     manifest.RootEntry,
+				listings: map[string][]kopiaEntry{},
+			}, nil
+		}
+	}
+	return nil, errors.Wrap(ErrSnapshotNotFound, snapshotID)
+}
+
+// RunKopia runs kopia binary from PATH
+func RunKopia(ctx context.Context, args ...string) (io.ReadCloser, error) {
+	cmd := exec.CommandContext(ctx, "kopia", args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "Unable to run kopia")
+	}
+	return &commandOutput{ReadCloser: stdout, cmd: cmd, stderr: stderr}, nil
+}
+
+// commandOutput waits for the command to finish when closed
+type commandOutput struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+}
+
+func (output *commandOutput) Close() error {
+	// Drain the output so the command does not block on writes
+	_, _ = io.Copy(io.Discard, output.ReadCloser)
+	if err := output.cmd.Wait(); err != nil {
+		return errors.Wrapf(err, "kopia %v failed: %s", output.cmd.Args[1:], bytes.TrimSpace(output.stderr.Bytes()))
+	}
+	return nil
+}
+
+func runJSON(ctx context.Context, run CommandRunner, result any, args ...string) error {
+	output, err := run(ctx, args...)
+	if err != nil {
+		return err
+	}
+	decodeErr := json.NewDecoder(output).Decode(result)
+	if err := output.Close(); err != nil {
+		return err
+	}
+	return errors.Wrap(decodeErr, "Unable to parse kopia output")
+}
+
+func (snapshot *KopiaSnapshot) List(ctx context.Context, dirPath string) ([]Entry, error) {
+	dir, err := snapshot.find(ctx, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	if dir.Type != "d" {
+		return nil, ErrNotDirectory
+	}
+	children, err := snapshot.listDirectory(ctx, dir.ObjectID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(children))
+	for _, child := range children {
+		entries = append(entries, child.toEntry(path.Join(dirPath, child.Name)))
+	}
+	return entries, nil
+}
+
+func (snapshot *KopiaSnapshot) Stat(ctx context.Context, entryPath string) (*Entry, error) {
+	found, err := snapshot.find(ctx, entryPath)
+	if err != nil {
+		return nil, err
+	}
+	entry := found.toEntry(entryPath)
+	return &entry, nil
+}
+
+func (snapshot *KopiaSnapshot) Open(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	file, err := snapshot.find(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if file.Type == "d" {
+		return nil, ErrIsDirectory
+	}
+	return snapshot.run(ctx, "show", file.ObjectID)
+}
+
+// find walks directories from the snapshot root to the entry with clean path
+func (snapshot *KopiaSnapshot) find(ctx context.Context, entryPath string) (kopiaEntry, error) {
+	current := snapshot.root
+	for _, name := range SplitPath(entryPath) {
+		if current.Type != "d" {
+			return kopiaEntry{}, ErrNotFound
+		}
+		children, err := snapshot.listDirectory(ctx, current.ObjectID)
+		if err != nil {
+			return kopiaEntry{}, err
+		}
+		found := false
+		for _, child := range children {
+			if child.Name == name {
+				current = child
+				found = true
+				break
+			}
+		}
+		if !found {
+			return kopiaEntry{}, ErrNotFound
+		}
+	}
+	return current, nil
+}
+
+// listDirectory reads the directory object, which is stored as JSON in kopia repository
+func (snapshot *KopiaSnapshot) listDirectory(ctx context.Context, objectID string) ([]kopiaEntry, error) {
+	snapshot.mutex.Lock()
+	cached, ok := snapshot.listings[objectID]
+	snapshot.mutex.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	dir := kopiaDirectory{}
+	if err := runJSON(ctx, snapshot.run, &dir, "show", objectID); err != nil {
+		return nil, errors.Wrap(err, "Unable to read directory")
+	}
+
+	snapshot.mutex.Lock()
+	defer snapshot.mutex.Unlock()
+	if len(snapshot.listings) >= kopiaListingCacheSize {
+		snapshot.listings = map[string][]kopiaEntry{}
+	}
+	snapshot.listings[objectID] = dir.Entries
+	return dir.Entries, nil
+}
+
+func (entry kopiaEntry) toEntry(entryPath string) Entry {
+	result := Entry{
+		Name:    path.Base("/" + entryPath),
+		Path:    entryPath,
+		Size:    entry.Size,
+		Mode:    entry.Mode,
+		ModTime: entry.ModTime,
+	}
+	switch entry.Type {
+	case "f":
+		result.Type = EntryTypeFile
+	case "d":
+		result.Type = EntryTypeDirectory
+		if entry.DirSummary != nil {
+			result.Size = entry.DirSummary.TotalFileSize
+		}
+	case "s":
+		result.Type = EntryTypeSymlink
+	default:
+		result.Type = EntryTypeOther
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package browse
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+// Snapshot with root directory containing db/data.bin and README
+var fakeKopiaOutputs = map[string]string{
+	"snapshot list --all --json": `[
+		{"id":"other","rootEntry":{"name":"data","type":"d","obj":"kother"}},
+		{"id":"k123","rootEntry":{"name":"data","type":"d","obj":"kroot","summ":{"size":15}}}
+	]`,
+	"show kroot": `{"stream":"kopia:directory","entries":[
+		{"name":"README","type":"f","mode":"0644","mtime":"2024-01-02T03:04:05Z","obj":"readme","size":5},
+		{"name":"db","type":"d","mode":"0755","mtime":"2024-01-02T03:04:05Z","obj":"kdb","summ":{"size":10}}
+	]}`,
+	"show kdb": `{"stream":"kopia:directory","entries":[
+		{"name":"data.bin","type":"f","mode":"0600","mtime":"2024-01-02T03:04:05Z","obj":"data","size":10}
+	]}`,
+	"show readme": "hello",
+	"show data":   "0123456789",
+}
+
+func makeFakeRunner(calls *[]string) CommandRunner {
+	return func(_ context.Context, args ...string) (io.ReadCloser, error) {
+		command := strings.Join(args, " ")
+		if calls != nil {
+			*calls = append(*calls, command)
+		}
+		output, ok := fakeKopiaOutputs[command]
+		if !ok {
+			return nil, errors.New("unexpected command " + command)
+		}
+		return io.NopCloser(strings.NewReader(output)), nil
+	}
+}
+
+func TestKopiaSnapshot(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	calls := []string{}
+	snapshot, err := NewKopiaSnapshot(ctx, "k123", makeFakeRunner(&calls))
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+
+	entries, err := snapshot.List(ctx, "")
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(entries).To(gomega.HaveLen(2))
+	matcher.Expect(entries[1]).To(gomega.And(
+		gomega.HaveField("Path", "db"),
+		gomega.HaveField("Type", EntryTypeDirectory),
+		gomega.HaveField("Size", int64(10)),
+	))
+
+	entry, err := snapshot.Stat(ctx, "db/data.bin")
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(entry.Name).To(gomega.Equal("data.bin"))
+	matcher.Expect(entry.Type).To(gomega.Equal(EntryTypeFile))
+	matcher.Expect(entry.Mode).To(gomega.Equal("0600"))
+
+	reader, err := snapshot.Open(ctx, "db/data.bin")
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	data, err := io.ReadAll(reader)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(string(data)).To(gomega.Equal("0123456789"))
+
+	// Directory listings are cached
+	matcher.Expect(calls).To(gomega.Equal([]string{"snapshot list --all --json", "show kroot", "show kdb", "show data"}))
+
+	_, err = snapshot.Stat(ctx, "db/missing")
+	matcher.Expect(err).To(gomega.MatchError(ErrNotFound))
+	_, err = snapshot.Stat(ctx, "README/file")
+	matcher.Expect(err).To(gomega.MatchError(ErrNotFound))
+	_, err = snapshot.List(ctx, "README")
+	matcher.Expect(err).To(gomega.MatchError(ErrNotDirectory))
+	_, err = snapshot.Open(ctx, "db")
+	matcher.Expect(err).To(gomega.MatchError(ErrIsDirectory))
+}
+
+func TestKopiaSnapshotNotFound(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	_, err := NewKopiaSnapshot(context.Background(), "missing", makeFakeRunner(nil))
+	matcher.Expect(errors.Is(err, ErrSnapshotNotFound)).To(gomega.BeTrue())
+}
@@ -0,0 +1,125 @@
+package browse
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	HealthPath   = "/healthz"
+	ListPath     = "/api/v1/list"
+	StatPath     = "/api/v1/stat"
+	DownloadPath = "/api/v1/download"
+)
+
+// NewHandler returns the HTTP handler serving the browse API for the snapshot.
+// API requests should pass the token in the Authorization: Bearer header,
+// health check is not authenticated for the readiness probe.
+func NewHandler(snapshot Snapshot, token string) http.Handler {
+	server := handler{snapshot: snapshot, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+HealthPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET "+ListPath, server.authenticated(server.list))
+	mux.HandleFunc("GET "+StatPath, server.authenticated(server.stat))
+	mux.HandleFunc("GET "+DownloadPath, server.authenticated(server.download))
+	return mux
+}
+
+type handler struct {
+	snapshot Snapshot
+	token    string
+}
+
+// authenticated rejects requests without the token, all requests are rejected if the token is empty
+func (h handler) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if h.token == "" || !found || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, ErrUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (h handler) list(w http.ResponseWriter, r *http.Request) {
+	dirPath := CleanPath(r.URL.Query().Get("path"))
+	entries, err := h.snapshot.List(r.Context(), dirPath)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, ListResponse{Path: dirPath, Entries: entries})
+}
+
+func (h handler) stat(w http.ResponseWriter, r *http.Request) {
+	entry, err := h.snapshot.Stat(r.Context(), CleanPath(r.URL.Query().Get("path")))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, entry)
+}
+
+func (h handler) download(w http.ResponseWriter, r *http.Request) {
+	filePath := CleanPath(r.URL.Query().Get("path"))
+	entry, err := h.snapshot.Stat(r.Context(), filePath)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if entry.Type != EntryTypeFile {
+		writeError(w, ErrIsDirectory)
+		return
+	}
+	reader, err := h.snapshot.Open(r.Context(), filePath)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer reader.Close() //nolint:errcheck
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(entry.Size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(filePath)))
+	if _, err := io.Copy(w, reader); err != nil {
+		// Headers are sent, client sees a truncated response
+		log.Log.Info("Failed to download file", "path", filePath, "error", err.Error())
+	}
+}
+
+func writeJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Log.Info("Failed to write response", "error", err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrNotDirectory), errors.Is(err, ErrIsDirectory):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		status = http.StatusUnauthorized
+	default:
+		log.Log.Error(err, "Browse request failed")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,100 @@
+package browse
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestCleanPath(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	matcher.Expect(CleanPath("")).To(gomega.Equal(""))
+	matcher.Expect(CleanPath("/")).To(gomega.Equal(""))
+	matcher.Expect(CleanPath("db/")).To(gomega.Equal("db"))
+	matcher.Expect(CleanPath("/db/./data.bin")).To(gomega.Equal("db/data.bin"))
+	matcher.Expect(CleanPath("../../etc")).To(gomega.Equal("etc"))
+}
+
+func TestHandler(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	snapshot, err := NewKopiaSnapshot(context.Background(), "k123", makeFakeRunner(nil))
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	server := httptest.NewServer(NewHandler(snapshot, "secret-token"))
+	defer server.Close()
+
+	get := func(apiPath, entryPath string) (*http.Response, []byte) {
+		request, err := http.NewRequest(http.MethodGet, server.URL+apiPath+"?path="+url.QueryEscape(entryPath), nil)
+		matcher.Expect(err).ToNot(gomega.HaveOccurred())
+		request.Header.Set("Authorization", "Bearer secret-token")
+		response, err := http.DefaultClient.Do(request)
+		matcher.Expect(err).ToNot(gomega.HaveOccurred())
+		defer response.Body.Close() //nolint:errcheck
+		body, err := io.ReadAll(response.Body)
+		matcher.Expect(err).ToNot(gomega.HaveOccurred())
+		return response, body
+	}
+
+	response, _ := get(HealthPath, "")
+	matcher.Expect(response.StatusCode).To(gomega.Equal(http.StatusOK))
+
+	response, body := get(ListPath, "/db")
+	matcher.Expect(response.StatusCode).To(gomega.Equal(http.StatusOK))
+	list := ListResponse{}
+	matcher.Expect(json.Unmarshal(body, &list)).To(gomega.Succeed())
+	matcher.Expect(list.Path).To(gomega.Equal("db"))
+	matcher.Expect(list.Entries).To(gomega.ConsistOf(gomega.HaveField("Path", "db/data.bin")))
+
+	response, body = get(StatPath, "README")
+	matcher.Expect(response.StatusCode).To(gomega.Equal(http.StatusOK))
+	entry := Entry{}
+	matcher.Expect(json.Unmarshal(body, &entry)).To(gomega.Succeed())
+	matcher.Expect(entry.Size).To(gomega.Equal(int64(5)))
+
+	response, body = get(DownloadPath, "db/data.bin")
+	matcher.Expect(response.StatusCode).To(gomega.Equal(http.StatusOK))
+	matcher.Expect(response.Header.Get("Content-Disposition")).To(gomega.Equal(`attachment; filename="data.bin"`))
+	matcher.Expect(string(body)).To(gomega.Equal("0123456789"))
+
+	response, body = get(StatPath, "missing")
+	matcher.Expect(response.StatusCode).To(gomega.Equal(http.StatusNotFound))
+	errorResponse := ErrorResponse{}
+	matcher.Expect(json.Unmarshal(body, &errorResponse)).To(gomega.Succeed())
+	matcher.Expect(errorResponse.Error).To(gomega.Equal(ErrNotFound.Error()))
+
+	response, _ = get(DownloadPath, "db")
+	matcher.Expect(response.StatusCode).To(gomega.Equal(http.StatusBadRequest))
+	response, _ = get(ListPath, "README")
+	matcher.Expect(response.StatusCode).To(gomega.Equal(http.StatusBadRequest))
+}
+
+func TestHandlerRequiresToken(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	snapshot, err := NewKopiaSnapshot(context.Background(), "k123", makeFakeRunner(nil))
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	server := httptest.NewServer(NewHandler(snapshot, "secret-token"))
+	defer server.Close()
+
+	status := func(apiPath, authorization string) int {
+		request, err := http.NewRequest(http.MethodGet, server.URL+apiPath, nil)
+		matcher.Expect(err).ToNot(gomega.HaveOccurred())
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		response, err := http.DefaultClient.Do(request)
+		matcher.Expect(err).ToNot(gomega.HaveOccurred())
+		defer response.Body.Close() //nolint:errcheck
+		return response.StatusCode
+	}
+
+	matcher.Expect(status(HealthPath, "")).To(gomega.Equal(http.StatusOK))
+	matcher.Expect(status(ListPath, "")).To(gomega.Equal(http.StatusUnauthorized))
+	matcher.Expect(status(ListPath, "Bearer wrong-token")).To(gomega.Equal(http.StatusUnauthorized))
+	matcher.Expect(status(DownloadPath, "secret-token")).To(gomega.Equal(http.StatusUnauthorized))
+	matcher.Expect(status(ListPath, "Bearer secret-token")).To(gomega.Equal(http.StatusOK))
+}
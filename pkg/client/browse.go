@@ -0,0 +1,93 @@
+package client
+
+import (
+	"path"
+	"strconv"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/kanisterio/datamover/pkg/browse"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// BrowsePortName is the name of the browse API port of the main container
+	BrowsePortName    = "browse"
+	DefaultBrowsePort = int32(8080)
+	// Browse API token is mounted from the secret to this file
+	BrowseTokenFile             = "/etc/browse-token/token"
+	defaultBrowseTokenSecretKey = "token"
+)
+
+// serverOperation is implemented by long-running operations serving an API from the main container
+type serverOperation interface {
+	makePorts() []corev1.ContainerPort
+	makeReadinessProbe() *corev1.Probe
+}
+
+var _ serverOperation = BrowseServerOperation{}
+
+// BrowseServerOperation mounts the browse API token from the secret to BrowseTokenFile
+func (server BrowseServerOperation) MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
+	volume, volumeMount := makeBrowseTokenVolume(server.Auth)
+	return []corev1.Volume{volume}, []corev1.VolumeMount{volumeMount}, nil
+}
+
+func makeBrowseTokenVolume(auth api.BrowseAuth) (corev1.Volume, corev1.VolumeMount) {
+	key := auth.Key
+	if key == "" {
+		key = defaultBrowseTokenSecretKey
+	}
+	volume := corev1.Volume{
+		Name: "browse-token",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: auth.SecretName,
+				Items:      []corev1.KeyToPath{{Key: key, Path: path.Base(BrowseTokenFile)}},
+			},
+		},
+	}
+	volumeMount := corev1.VolumeMount{
+		Name:      volume.Name,
+		MountPath: path.Dir(BrowseTokenFile),
+		ReadOnly:  true,
+	}
+	return volume, volumeMount
+}
+
+func (server BrowseServerOperation) MakeArgs() []string {
+	return []string{
+		OpBrowseServer,
+		server.BackupID,
+		strconv.Itoa(int(server.port())),
+		BrowseTokenFile,
+	}
+}
+
+func (server BrowseServerOperation) port() int32 {
+	if server.Port == 0 {
+		return DefaultBrowsePort
+	}
+	return server.Port
+}
+
+func (server BrowseServerOperation) makePorts() []corev1.ContainerPort {
+	return []corev1.ContainerPort{{
+		Name:          BrowsePortName,
+		ContainerPort: server.port(),
+		Protocol:      corev1.ProtocolTCP,
+	}}
+}
+
+// Pod is ready once the snapshot is found and the server is listening
+func (server BrowseServerOperation) makeReadinessProbe() *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: browse.HealthPath,
+				Port: intstr.FromString(BrowsePortName),
+			},
+		},
+		PeriodSeconds: 5,
+	}
+}
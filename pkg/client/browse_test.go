@@ -0,0 +1,48 @@
+package client
+
+import (
+	"testing"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/kanisterio/datamover/pkg/browse"
+	"github.com/kanisterio/datamover/pkg/session"
+	"github.com/onsi/gomega"
+)
+
+func TestBrowseServerOperation(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	operation, err := OperationFromSpec(api.DatamoverOperationSpec{
+		Type:   api.OperationTypeBrowse,
+		Browse: &api.BrowseParams{BackupID: "k123", Auth: api.BrowseAuth{SecretName: "browse-token"}},
+	})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(operation.MakeArgs()).To(gomega.Equal([]string{OpBrowseServer, "k123", "8080", BrowseTokenFile}))
+
+	pod, err := MakeClientPod(CreateClientArgs{
+		Operation:         operation,
+		Image:             "client",
+		CredentialsConfig: ClientCredentialsToken{},
+	}, session.SessionConfig{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	container := pod.Spec.Containers[0]
+	matcher.Expect(container.Ports).To(gomega.ConsistOf(gomega.And(
+		gomega.HaveField("Name", BrowsePortName),
+		gomega.HaveField("ContainerPort", DefaultBrowsePort),
+	)))
+	matcher.Expect(container.ReadinessProbe.HTTPGet.Path).To(gomega.Equal(browse.HealthPath))
+	matcher.Expect(container.VolumeMounts).To(gomega.ContainElement(gomega.HaveField("MountPath", "/etc/browse-token")))
+	matcher.Expect(pod.Spec.Volumes).To(gomega.ContainElement(gomega.And(
+		gomega.HaveField("Secret.SecretName", "browse-token"),
+		gomega.HaveField("Secret.Items", gomega.ConsistOf(gomega.HaveField("Key", "token"))),
+	)))
+
+	// Other operations do not expose ports
+	pod, err = MakeClientPod(CreateClientArgs{
+		Operation:         FileSystemBackupOperation{PVC: "data", Path: "/"},
+		Image:             "client",
+		CredentialsConfig: ClientCredentialsToken{},
+	}, session.SessionConfig{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(pod.Spec.Containers[0].Ports).To(gomega.BeEmpty())
+	matcher.Expect(pod.Spec.Containers[0].ReadinessProbe).To(gomega.BeNil())
+}
@@ -3,9 +3,11 @@ package client
 import (
 	"context"
 	"fmt"
+	"maps"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -13,9 +15,11 @@ import (
 	"github.com/kanisterio/datamover/pkg/podoverride"
 	"github.com/kanisterio/datamover/pkg/session"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 const NamePrefix string = "dm-client-"
@@ -126,6 +130,11 @@ type CreateClientArgs struct {
 	CredentialsConfig ClientCredentialsConfig
 	Env               []corev1.EnvVar
 	PodOptions        api.PodOptions
+	// Interval to report progress with the progress sidecar, progress is not reported if zero
+	// Progress sidecar is a native sidecar container, which requires Kubernetes 1.29+
+	ProgressInterval time.Duration
+	// Image of the progress sidecar, defaults to busybox
+	ProgressImage string
 }
 
 func CreateClientPod(
@@ -133,9 +142,18 @@ func CreateClientPod(
 	cli kubernetes.Interface,
 	dynCli dynamic.Interface,
 	clientArgs CreateClientArgs,
+) (*corev1.Pod, error) {
+	return CreateClientPodWithClient(ctx, cli, session.NewDynamicClient(dynCli), clientArgs)
+}
+
+func CreateClientPodWithClient(
+	ctx context.Context,
+	cli kubernetes.Interface,
+	sessionCli session.Client,
+	clientArgs CreateClientArgs,
 ) (*corev1.Pod, error) {
 	// FIXME: require readiness check beforehand instead of waiting here
-	sessionConfig, err := session.GetConfig(ctx, dynCli, clientArgs.SessionName, clientArgs.SessionNamespace)
+	sessionConfig, err := session.GetConfigWithClient(ctx, sessionCli, clientArgs.SessionName, clientArgs.SessionNamespace)
 	if err != nil {
 		return nil, errors.Wrap(err, "Cannot extract datamover session config")
 	}
@@ -148,6 +166,13 @@ func CreateClientPod(
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to run client pod")
 	}
+	if err := CheckProgressSidecar(pod.Spec); err != nil {
+		// Pod would never start
+		if err := cli.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			log.Log.Info("Unable to delete client pod", "pod", pod.Name, "error", err.Error())
+		}
+		return nil, err
+	}
 	return pod, nil
 }
 
@@ -182,18 +207,29 @@ func MakeClientPod(
 		})
 	}
 
+	progressVolumes := []corev1.Volume{}
+	progressVolumeMounts := []corev1.VolumeMount{}
+	progressContainers := []corev1.Container{}
+	if clientArgs.ProgressInterval > 0 {
+		progressVolumes = append(progressVolumes, makeProgressVolume())
+		progressVolumeMounts = append(progressVolumeMounts, progressVolumeMount())
+		progressContainers = append(progressContainers, makeProgressSidecar(clientArgs.ProgressInterval, clientArgs.ProgressImage))
+		envs = append(envs, progressEnv())
+	}
+
 	// TODO: handle name clashes in volumes better
-	err = validateVolumeNames([][]corev1.Volume{secretVolumes, clientVolumes, operationVolumes, configVolumes, extraVolumes})
+	err = validateVolumeNames([][]corev1.Volume{secretVolumes, clientVolumes, operationVolumes, configVolumes, extraVolumes, progressVolumes})
 	if err != nil {
 		return nil, err
 	}
 
-	volumes := slices.Concat(secretVolumes, clientVolumes, operationVolumes, configVolumes, extraVolumes)
+	volumes := slices.Concat(secretVolumes, clientVolumes, operationVolumes, configVolumes, extraVolumes, progressVolumes)
 
-	volumeMounts := slices.Concat(secretVolumeMounts, clientVolumeMounts, operationVolumeMounts, configVolumeMounts, extraVolumeMounts)
+	volumeMounts := slices.Concat(secretVolumeMounts, clientVolumeMounts, operationVolumeMounts, configVolumeMounts, extraVolumeMounts, progressVolumeMounts)
 
 	operationContainers := clientArgs.Operation.MakeContainers()
-	operationInitContainers := clientArgs.Operation.MakeInitContainers()
+	// Progress sidecar starts after operation init containers
+	operationInitContainers := slices.Concat(clientArgs.Operation.MakeInitContainers(), progressContainers)
 
 	mainContainer := corev1.Container{
 		Name:          MainContainerName,
@@ -205,6 +241,16 @@ func MakeClientPod(
 
 		SecurityContext: clientArgs.PodOptions.ContainerSecurityContext,
 		Resources:       clientArgs.PodOptions.Resources,
+
+		// Client writes operation result to ResultPath
+		// Use last log lines as error message if it fails without writing it
+		TerminationMessagePath:   ResultPath,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+
+	if server, ok := clientArgs.Operation.(serverOperation); ok {
+		mainContainer.Ports = server.makePorts()
+		mainContainer.ReadinessProbe = server.makeReadinessProbe()
 	}
 
 	containers := slices.Concat(
@@ -241,13 +287,27 @@ func MakeClientPod(
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: genName,
 			Namespace:    clientArgs.Namespace,
-			Labels:       clientArgs.PodOptions.Labels,
+			Labels:       clientLabels(clientArgs.PodOptions.Labels, sessionConfig),
 			Annotations:  clientArgs.PodOptions.Annotations,
 		},
 		Spec: podSpec,
 	}, nil
 }
 
+// clientLabels adds session client label, which can be used
+// by session network policy to allow connections from the client
+func clientLabels(labels map[string]string, sessionConfig session.SessionConfig) map[string]string {
+	if sessionConfig.SessionUID == "" {
+		return labels
+	}
+	result := maps.Clone(labels)
+	if result == nil {
+		result = map[string]string{}
+	}
+	result[api.DatamoverClientLabel] = string(sessionConfig.SessionUID)
+	return result
+}
+
 func sessionConfigEnvs(sessionConfig session.SessionConfig) []corev1.EnvVar {
 	service := sessionConfig.Service
 	url := ""
@@ -260,7 +320,7 @@ func sessionConfigEnvs(sessionConfig session.SessionConfig) []corev1.EnvVar {
 		}
 		protocols = strings.Join(stringProtocols, ";")
 
-		url = fmt.Sprintf("%s.%s.svc.cluster.local", service.Name, service.Namespace)
+		url = session.ServiceHostname(*service, sessionConfig.ClusterDomain)
 	}
 
 	return []corev1.EnvVar{
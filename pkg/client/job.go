@@ -0,0 +1,251 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/kanisterio/datamover/pkg/session"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// InvalidConfigExitCode is used by implementations to report invalid configuration
+const InvalidConfigExitCode int32 = 22
+
+// ErrClientJobDeleted is returned when the client job is deleted before it finishes
+var ErrClientJobDeleted = errors.New("Client job was deleted")
+
+// JobOptions configure the Job running the client pod
+type JobOptions struct {
+	// Number of retries of failed pods, see Job spec backoffLimit
+	BackoffLimit *int32
+	// Time limit for the whole job including retries
+	ActiveDeadlineSeconds *int64
+	// Delete the job and its pods after it finishes
+	TTLSecondsAfterFinished *int32
+	// Exit codes of the main container which fail the job without retries.
+	// Defaults to InvalidConfigExitCode.
+	NonRetriableExitCodes []int32
+}
+
+func CreateClientJob(
+	ctx context.Context,
+	cli kubernetes.Interface,
+	dynCli dynamic.Interface,
+	clientArgs CreateClientArgs,
+	jobOptions JobOptions,
+) (*batchv1.Job, error) {
+	return CreateClientJobWithClient(ctx, cli, session.NewDynamicClient(dynCli), clientArgs, jobOptions)
+}
+
+func CreateClientJobWithClient(
+	ctx context.Context,
+	cli kubernetes.Interface,
+	sessionCli session.Client,
+	clientArgs CreateClientArgs,
+	jobOptions JobOptions,
+) (*batchv1.Job, error) {
+	sessionConfig, err := session.GetConfigWithClient(ctx, sessionCli, clientArgs.SessionName, clientArgs.SessionNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot extract datamover session config")
+	}
+
+	job, err := MakeClientJob(clientArgs, *sessionConfig, jobOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to generate client job spec")
+	}
+	job, err = cli.BatchV1().Jobs(clientArgs.Namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to run client job")
+	}
+	if err := CheckProgressSidecar(job.Spec.Template.Spec); err != nil {
+		// Job pods would never start
+		if err := DeleteClientJob(ctx, cli, job); err != nil {
+			log.Log.Info("Unable to delete client job", "job", job.Name, "error", err.Error())
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// MakeClientJob wraps the client pod into a Job, so it's retried after failures.
+// Pod disruptions, e.g. node drains, are retried without counting towards backoffLimit.
+func MakeClientJob(
+	clientArgs CreateClientArgs,
+	sessionConfig session.SessionConfig,
+	jobOptions JobOptions,
+) (*batchv1.Job, error) {
+	pod, err := MakeClientPod(clientArgs, sessionConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: pod.GenerateName,
+			Namespace:    pod.Namespace,
+			Labels:       pod.Labels,
+			Annotations:  pod.Annotations,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            jobOptions.BackoffLimit,
+			ActiveDeadlineSeconds:   jobOptions.ActiveDeadlineSeconds,
+			TTLSecondsAfterFinished: jobOptions.TTLSecondsAfterFinished,
+			PodFailurePolicy:        makePodFailurePolicy(jobOptions.NonRetriableExitCodes),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      pod.Labels,
+					Annotations: pod.Annotations,
+				},
+				Spec: pod.Spec,
+			},
+		},
+	}, nil
+}
+
+func makePodFailurePolicy(nonRetriableExitCodes []int32) *batchv1.PodFailurePolicy {
+	exitCodes := slices.Clone(nonRetriableExitCodes)
+	if len(exitCodes) == 0 {
+		exitCodes = []int32{InvalidConfigExitCode}
+	}
+	// API requires sorted unique values
+	slices.Sort(exitCodes)
+	exitCodes = slices.Compact(exitCodes)
+	return &batchv1.PodFailurePolicy{
+		Rules: []batchv1.PodFailurePolicyRule{
+			{
+				Action: batchv1.PodFailurePolicyActionFailJob,
+				OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+					ContainerName: ptr.To(MainContainerName),
+					Operator:      batchv1.PodFailurePolicyOnExitCodesOpIn,
+					Values:        exitCodes,
+				},
+			},
+			{
+				Action: batchv1.PodFailurePolicyActionIgnore,
+				OnPodConditions: []batchv1.PodFailurePolicyOnPodConditionsPattern{
+					{Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue},
+				},
+			},
+		},
+	}
+}
+
+// WaitForJob waits for the client job to finish and returns the result reported by its pod.
+// If the job failed, returns ClientPodError of the last failed pod.
+func WaitForJob(ctx context.Context, cli kubernetes.Interface, job *batchv1.Job) (*api.OperationResult, error) {
+	finished, err := waitForJobFinished(ctx, cli, job)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := listJobPods(ctx, cli, *finished)
+	if err != nil {
+		return nil, err
+	}
+
+	if condition := jobCondition(*finished, batchv1.JobComplete); condition != nil {
+		for _, pod := range pods {
+			if pod.Status.Phase == corev1.PodSucceeded {
+				return ParseResult(pod)
+			}
+		}
+		return nil, errors.New("Client job completed without succeeded pods")
+	}
+
+	reason := "unknown"
+	if condition := jobCondition(*finished, batchv1.JobFailed); condition != nil {
+		reason = strings.TrimSpace(condition.Reason + " " + condition.Message)
+	}
+	failed := slices.DeleteFunc(pods, func(pod corev1.Pod) bool {
+		return pod.Status.Phase != corev1.PodFailed
+	})
+	if len(failed) == 0 {
+		return nil, errors.New("Client job failed: " + reason)
+	}
+	lastFailed := slices.MaxFunc(failed, func(a, b corev1.Pod) int {
+		return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+	})
+	result, err := finishedResult(ctx, cli, lastFailed, 0)
+	return result, errors.Wrap(err, "Client job failed: "+reason)
+}
+
+// DeleteClientJob deletes the client job with its pods, not found job is not an error
+func DeleteClientJob(ctx context.Context, cli kubernetes.Interface, job *batchv1.Job) error {
+	err := cli.BatchV1().Jobs(job.Namespace).Delete(ctx, job.Name, metav1.DeleteOptions{
+		PropagationPolicy: ptr.To(metav1.DeletePropagationBackground),
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "Unable to delete client job")
+	}
+	return nil
+}
+
+func waitForJobFinished(ctx context.Context, cli kubernetes.Interface, job *batchv1.Job) (*batchv1.Job, error) {
+	selector := fields.OneTermEqualSelector("metadata.name", job.Name).String()
+	jobs := cli.BatchV1().Jobs(job.Namespace)
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector
+			return jobs.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector
+			return jobs.Watch(ctx, options)
+		},
+	}
+	event, err := watchtools.UntilWithSync(ctx, lw, &batchv1.Job{}, nil, func(event watch.Event) (bool, error) {
+		current, ok := event.Object.(*batchv1.Job)
+		if !ok || current.Name != job.Name {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			return false, ErrClientJobDeleted
+		}
+		return jobCondition(*current, batchv1.JobComplete) != nil || jobCondition(*current, batchv1.JobFailed) != nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event.Object.(*batchv1.Job), nil
+}
+
+func jobCondition(job batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return &condition
+		}
+	}
+	return nil
+}
+
+func listJobPods(ctx context.Context, cli kubernetes.Interface, job batchv1.Job) ([]corev1.Pod, error) {
+	// Selector is generated by the API server
+	selector := labels.SelectorFromSet(labels.Set{batchv1.JobNameLabel: job.Name})
+	if job.Spec.Selector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(job.Spec.Selector)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid client job selector")
+		}
+	}
+	pods, err := cli.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to list client job pods")
+	}
+	return pods.Items, nil
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kanisterio/datamover/pkg/session"
+	"github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func makeJobArgs() CreateClientArgs {
+	return CreateClientArgs{
+		Operation:         FileSystemBackupOperation{Path: "/", PVC: "data"},
+		Namespace:         "ns",
+		Image:             "client",
+		CredentialsConfig: ClientCredentialsToken{},
+	}
+}
+
+func TestMakeClientJob(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	job, err := MakeClientJob(makeJobArgs(), session.SessionConfig{}, JobOptions{
+		BackoffLimit:            ptr.To[int32](2),
+		ActiveDeadlineSeconds:   ptr.To[int64](3600),
+		TTLSecondsAfterFinished: ptr.To[int32](60),
+		NonRetriableExitCodes:   []int32{22, 3, 22},
+	})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(job.GenerateName).To(gomega.Equal(NamePrefix))
+	matcher.Expect(*job.Spec.BackoffLimit).To(gomega.BeEquivalentTo(2))
+	matcher.Expect(*job.Spec.ActiveDeadlineSeconds).To(gomega.BeEquivalentTo(3600))
+	matcher.Expect(*job.Spec.TTLSecondsAfterFinished).To(gomega.BeEquivalentTo(60))
+	matcher.Expect(job.Spec.Template.Spec.RestartPolicy).To(gomega.Equal(corev1.RestartPolicyNever))
+	matcher.Expect(job.Spec.Template.Spec.Containers[0].Name).To(gomega.Equal(MainContainerName))
+
+	rules := job.Spec.PodFailurePolicy.Rules
+	matcher.Expect(rules).To(gomega.HaveLen(2))
+	matcher.Expect(rules[0].Action).To(gomega.Equal(batchv1.PodFailurePolicyActionFailJob))
+	matcher.Expect(rules[0].OnExitCodes.Values).To(gomega.Equal([]int32{3, 22}))
+	matcher.Expect(rules[1].Action).To(gomega.Equal(batchv1.PodFailurePolicyActionIgnore))
+}
+
+func makeFinishedJob(conditionType batchv1.JobConditionType) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "ns"},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{
+				Type:   conditionType,
+				Status: corev1.ConditionTrue,
+				Reason: "PodFailurePolicy",
+			}},
+		},
+	}
+}
+
+func makeJobPod(name string, phase corev1.PodPhase, exitCode int32, message string) *corev1.Pod {
+	pod := makeFinishedPod(phase, message)
+	pod.Name = name
+	pod.Labels = map[string]string{batchv1.JobNameLabel: "job"}
+	pod.Status.ContainerStatuses[0].State.Terminated.ExitCode = exitCode
+	return pod
+}
+
+func TestWaitForJobResult(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	cli := kubefake.NewSimpleClientset(
+		makeFinishedJob(batchv1.JobComplete),
+		makeJobPod("job-1", corev1.PodFailed, 1, "connection reset"),
+		makeJobPod("job-2", corev1.PodSucceeded, 0, `{"snapshotID":"k123"}`),
+	)
+
+	result, err := WaitForJob(context.Background(), cli, &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "ns"}})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(result.SnapshotID).To(gomega.Equal("k123"))
+}
+
+func TestWaitForJobFailed(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	cli := kubefake.NewSimpleClientset(
+		makeFinishedJob(batchv1.JobFailed),
+		makeJobPod("job-1", corev1.PodFailed, InvalidConfigExitCode, `{"errors":["Failed to connect to repository"]}`),
+	)
+
+	result, err := WaitForJob(context.Background(), cli, &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "ns"}})
+	matcher.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("PodFailurePolicy")))
+	matcher.Expect(result.Errors).To(gomega.ConsistOf("Failed to connect to repository"))
+}
@@ -2,7 +2,11 @@ package client
 
 import (
 	"path"
+	"slices"
+	"strconv"
+	"strings"
 
+	api "github.com/kanisterio/datamover/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -11,6 +15,15 @@ const (
 	StreamFileDir          = "/tmp/stream_file/"
 	StreamFileName         = "data"
 	streamDefaultInitImage = "busybox:latest"
+
+	// Init container creating the stream file
+	StreamInitContainerName = "initstreamfile"
+
+	// Restored stream is buffered here until its checksum is verified
+	StreamBufferDir = "/tmp/stream_buffer/"
+	// Stream encryption key is mounted from the secret to this file
+	EncryptionKeyFile          = "/etc/stream-encryption/key"
+	defaultEncryptionSecretKey = "key"
 )
 
 const (
@@ -21,6 +34,19 @@ const (
 	OpStreamRestore = "stream_restore"
 )
 
+const (
+	OpMultiVolumeBackup  = "multi_volume_backup"
+	OpMultiVolumeRestore = "multi_volume_restore"
+	OpBlockBackup        = "block_backup"
+	OpBlockRestore       = "block_restore"
+	OpBrowseServer       = "browse_server"
+	OpMultiStreamBackup  = "multi_stream_backup"
+	OpMultiStreamRestore = "multi_stream_restore"
+)
+
+// BlockDevicePath is the path block volumes are attached to in the client container
+const BlockDevicePath = "/dev/datamover-block"
+
 type Operation interface {
 	MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice)
 	MakeArgs() []string
@@ -33,11 +59,11 @@ var _ Operation = FileSystemRestoreOperation{}
 
 // FileSystemBackupOperation backs up file system directory at Path from PVC
 type FileSystemBackupOperation struct {
-	Path string
-	// TODO: do we want to have extra metadata other than tag?
+	Path          string
 	Tag           string
 	PVC           string
 	ReadOnlyMount bool
+	Metadata      api.BackupMetadata
 }
 
 // FileSystemRestoreOperation restores file system directory to Path in PVC
@@ -45,14 +71,64 @@ type FileSystemRestoreOperation struct {
 	Path     string
 	BackupID string
 	PVC      string
+	// Paths in the snapshot to restore, the whole snapshot is restored if empty
+	Include []string
+	// Paths in the snapshot to skip
+	Exclude         []string
+	OverwritePolicy api.OverwritePolicy
+	SparseRestore   bool
+}
+
+var _ Operation = FileSystemSidecarOperation{}
+
+var _ Operation = MultiVolumeBackupOperation{}
+var _ Operation = MultiVolumeRestoreOperation{}
+
+// MultiVolumeBackupOperation backs up multiple PVCs into one snapshot
+// Volumes maps volume name to PVC, each PVC is mounted to /mnt/data/<name>
+type MultiVolumeBackupOperation struct {
+	Volumes       map[string]string
+	Tag           string
+	ReadOnlyMount bool
+}
+
+// MultiVolumeRestoreOperation restores volumes of a multi-volume backup
+// Volumes maps volume name in the backup to PVC to restore it to,
+// volumes missing in the map are not restored
+type MultiVolumeRestoreOperation struct {
+	Volumes  map[string]string
+	BackupID string
+}
+
+var _ Operation = BlockBackupOperation{}
+var _ Operation = BlockRestoreOperation{}
+
+// BlockBackupOperation backs up raw block volume PVC
+// PVC should have volumeMode: Block and is attached to BlockDevicePath
+type BlockBackupOperation struct {
+	PVC string
+	Tag string
+}
+
+// BlockRestoreOperation restores raw block volume backup to PVC
+// PVC should have volumeMode: Block and be at least as large as the backed up volume
+type BlockRestoreOperation struct {
+	PVC      string
+	BackupID string
+	// Skip writing zero blocks. Only safe if the volume reads as zeros, e.g. new thin provisioned volume
+	SparseRestore bool
 }
 
 // FileSystemSidecarOperation runs in sidecar containers and reads
 // backup or restore commands from a file descriptor with FileName
 // This operation is used when datamover container is running
 // as a sidecar to existing pod with data.
+// See SidecarCommand for the command protocol and InjectSidecar.
 type FileSystemSidecarOperation struct {
-	FileName    string
+	// Named pipe to read commands from, defaults to SidecarCommandFile
+	FileName string
+	// Mount of the data volume, mount path defaults to /mnt/data/data
+	// Command paths are relative to the mount path, which is passed to the implementation
 	VolumeMount corev1.VolumeMount
 }
 
@@ -62,14 +138,18 @@ var _ Operation = StreamRestoreOperation{}
 // StreamBackupOperation backs up data from StreamFile file
 // StreamGenerator container should generate data and send it to a file in StreamFileDir
 type StreamBackupOperation struct {
-	// TODO: do we want to have extra metadata other than tag?
-	Tag string
+	Tag      string
+	Metadata api.BackupMetadata
 	// TODO: how do we control vitrual file names??
 	StreamGenerator corev1.Container
 	// Identifier of a stream data in a backup (filename)
 	BackupObjectName string
 	// Optional: image to use for init container
 	InitImage string
+	// Optional: compression algorithm of the implementation
+	Compression string
+	// Optional: encrypt the stream with the key from the secret
+	Encryption *api.StreamEncryption
 }
 
 // StreamRestoreOperation restores data and writes in into a file in StreamFileDir
@@ -82,25 +162,115 @@ type StreamRestoreOperation struct {
 	BackupObjectName string
 	// Optional: image to use for init container
 	InitImage string
+	// Optional: encryption the stream was backed up with
+	Encryption *api.StreamEncryption
+	// Optional: checksum from the backup result to verify restored data against
+	// Restored data is buffered in StreamBufferDir until it's verified,
+	// encrypted data is always verified
+	Checksum string
 }
 
-// Example of another operation working with FLR APIs
-// type StartFLRServerOperation struct {
-// 	// TODO: what do we even need here?
-// }
+var _ Operation = MultiStreamBackupOperation{}
+var _ Operation = MultiStreamRestoreOperation{}
+
+// MultiStreamBackupOperation backs up several named streams into one backup
+// Stream generators write each stream to MultiStreamFileName(name)
+type MultiStreamBackupOperation struct {
+	Streams          []string
+	StreamGenerators []corev1.Container
+	Tag              string
+	// Optional: image to use for init container
+	InitImage string
+}
+
+// MultiStreamRestoreOperation restores streams of a multi-stream backup
+// Stream ingestors read each stream from MultiStreamFileName(name)
+type MultiStreamRestoreOperation struct {
+	Streams         []string
+	StreamIngestors []corev1.Container
+	BackupID        string
+	// Optional: image to use for init container
+	InitImage string
+}
+
+var _ Operation = BrowseServerOperation{}
+
+// BrowseServerOperation serves the backup over the browse HTTP API until the client pod is deleted
+// See pkg/browse for the API. Main container exposes the API with BrowsePortName port.
+type BrowseServerOperation struct {
+	BackupID string
+	// Defaults to DefaultBrowsePort
+	Port int32
+	// Secret with the token required by the browse API
+	Auth api.BrowseAuth
+}
 
 // StreamBackupOperation generates EmptyDir volume for communication between
 // the main container and data generator container
 // It also returns all configured volumes (for use in the generator) container
 func (streamBackup StreamBackupOperation) MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
 	streamFileVolume, streamFileVolumeMount := makeStreamFileVolume()
-	return []corev1.Volume{streamFileVolume}, []corev1.VolumeMount{streamFileVolumeMount}, nil
+	volumes, volumeMounts := []corev1.Volume{streamFileVolume}, []corev1.VolumeMount{streamFileVolumeMount}
+	if streamBackup.Encryption != nil {
+		keyVolume, keyVolumeMount := makeEncryptionKeyVolume(*streamBackup.Encryption)
+		volumes, volumeMounts = append(volumes, keyVolume), append(volumeMounts, keyVolumeMount)
+	}
+	return volumes, volumeMounts, nil
 }
 
 // StreamRestoreOperation generates EmptyDir volume for communication between
 // the main container and data ingestor container
 // It also returns all configured volumes (for use in the ingestor) container
 func (streamRestore StreamRestoreOperation) MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
+	streamFileVolume, streamFileVolumeMount := makeStreamFileVolume()
+	volumes, volumeMounts := []corev1.Volume{streamFileVolume}, []corev1.VolumeMount{streamFileVolumeMount}
+	if streamRestore.Encryption != nil {
+		keyVolume, keyVolumeMount := makeEncryptionKeyVolume(*streamRestore.Encryption)
+		volumes, volumeMounts = append(volumes, keyVolume), append(volumeMounts, keyVolumeMount)
+	}
+	if streamRestore.buffered() {
+		// Restored data is buffered on disk, not in memory like the stream file
+		volumes = append(volumes, corev1.Volume{
+			Name:         "stream-buffer",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "stream-buffer",
+			MountPath: StreamBufferDir,
+		})
+	}
+	return volumes, volumeMounts, nil
+}
+
+// makeEncryptionKeyVolume mounts the encryption key from the secret to EncryptionKeyFile
+func makeEncryptionKeyVolume(encryption api.StreamEncryption) (corev1.Volume, corev1.VolumeMount) {
+	key := encryption.Key
+	if key == "" {
+		key = defaultEncryptionSecretKey
+	}
+	volume := corev1.Volume{
+		Name: "stream-encryption-key",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: encryption.SecretName,
+				Items:      []corev1.KeyToPath{{Key: key, Path: path.Base(EncryptionKeyFile)}},
+			},
+		},
+	}
+	volumeMount := corev1.VolumeMount{
+		Name:      volume.Name,
+		MountPath: path.Dir(EncryptionKeyFile),
+		ReadOnly:  true,
+	}
+	return volume, volumeMount
+}
+
+func (backup MultiStreamBackupOperation) MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
+	streamFileVolume, streamFileVolumeMount := makeStreamFileVolume()
+	return []corev1.Volume{streamFileVolume}, []corev1.VolumeMount{streamFileVolumeMount}, nil
+}
+
+func (restore MultiStreamRestoreOperation) MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
 	streamFileVolume, streamFileVolumeMount := makeStreamFileVolume()
 	return []corev1.Volume{streamFileVolume}, []corev1.VolumeMount{streamFileVolumeMount}, nil
 }
@@ -122,7 +292,16 @@ func makeStreamFileVolume() (corev1.Volume, corev1.VolumeMount) {
 
 // File system sidecar operation relies on volume to be in the pod and returns a mount to it
 func (monitorFile FileSystemSidecarOperation) MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
-	return []corev1.Volume{}, []corev1.VolumeMount{monitorFile.VolumeMount}, nil
+	volumeMount := monitorFile.VolumeMount
+	volumeMount.MountPath = monitorFile.mountPath()
+	return []corev1.Volume{}, []corev1.VolumeMount{volumeMount}, nil
+}
+
+func (monitorFile FileSystemSidecarOperation) mountPath() string {
+	if monitorFile.VolumeMount.MountPath == "" {
+		return sidecarDataMountPath
+	}
+	return monitorFile.VolumeMount.MountPath
 }
 
 // Backup and restore operations generate "data" volume from PVC and mount it to /mnt/data/data
@@ -134,6 +313,45 @@ func (backup FileSystemBackupOperation) MakeVolumes() ([]corev1.Volume, []corev1
 	return makeDataPvcVolumes(backup.PVC, backup.ReadOnlyMount)
 }
 
+// Multi-volume operations mount each PVC to /mnt/data/<name>
+func (backup MultiVolumeBackupOperation) MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
+	return makePvcVolumes("/mnt/data/", backup.ReadOnlyMount, backup.Volumes)
+}
+
+func (restore MultiVolumeRestoreOperation) MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
+	return makePvcVolumes("/mnt/data/", false, restore.Volumes)
+}
+
+// Block operations attach "data" PVC as a device to BlockDevicePath
+func (backup BlockBackupOperation) MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
+	return makeBlockPvcVolumes(backup.PVC, true)
+}
+
+func (restore BlockRestoreOperation) MakeVolumes() ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
+	return makeBlockPvcVolumes(restore.PVC, false)
+}
+
+func makeBlockPvcVolumes(pvc string, readOnly bool) ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
+	volumes := []corev1.Volume{
+		{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: pvc,
+					ReadOnly:  readOnly,
+				},
+			},
+		},
+	}
+	volumeDevices := []corev1.VolumeDevice{
+		{
+			Name:       "data",
+			DevicePath: BlockDevicePath,
+		},
+	}
+	return volumes, nil, volumeDevices
+}
+
 func makeDataPvcVolumes(pvc string, readOnly bool) ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
 	return makePvcVolumes("/mnt/data/", readOnly, map[string]string{"data": pvc})
 }
@@ -141,7 +359,9 @@ func makeDataPvcVolumes(pvc string, readOnly bool) ([]corev1.Volume, []corev1.Vo
 func makePvcVolumes(prefix string, readOnly bool, pvcMap map[string]string) ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
 	volumes := []corev1.Volume{}
 	volumeMounts := []corev1.VolumeMount{}
-	for name, pvc := range pvcMap {
+	// Sorted for stable pod spec
+	for _, name := range sortedNames(pvcMap) {
+		pvc := pvcMap[name]
 		volumes = append(volumes, corev1.Volume{
 			Name: name,
 			VolumeSource: corev1.VolumeSource{
@@ -161,47 +381,184 @@ func makePvcVolumes(prefix string, readOnly bool, pvcMap map[string]string) ([]c
 }
 
 func (streamBackup StreamBackupOperation) MakeArgs() []string {
-	return []string{
+	args := []string{
 		OpStreamBackup,
 		streamFileName(),
 		streamBackup.BackupObjectName,
 		streamBackup.Tag,
 	}
+	args = append(args, metadataArgs(streamBackup.Metadata)...)
+	if streamBackup.Compression != "" {
+		args = append(args, "--compression="+streamBackup.Compression)
+	}
+	if streamBackup.Encryption != nil {
+		args = append(args, "--encryption-key="+EncryptionKeyFile)
+	}
+	return args
 }
 
 func (streamRestore StreamRestoreOperation) MakeArgs() []string {
-	return []string{
+	args := []string{
 		OpStreamRestore,
 		streamFileName(),
 		streamRestore.BackupObjectName,
 		streamRestore.BackupID,
 	}
+	if streamRestore.Encryption != nil {
+		args = append(args, "--encryption-key="+EncryptionKeyFile)
+	}
+	if streamRestore.Checksum != "" {
+		args = append(args, "--checksum="+streamRestore.Checksum)
+	}
+	if streamRestore.buffered() {
+		args = append(args, "--buffer-dir="+StreamBufferDir)
+	}
+	return args
+}
+
+// Restored data is verified before it's written to the stream
+func (streamRestore StreamRestoreOperation) buffered() bool {
+	return streamRestore.Encryption != nil || streamRestore.Checksum != ""
 }
 
 func streamFileName() string {
 	return path.Join(StreamFileDir, StreamFileName)
 }
 
-func (backup FileSystemBackupOperation) MakeArgs() []string {
+// MultiStreamFileName is the named pipe of the stream in multi-stream operations
+func MultiStreamFileName(name string) string {
+	return path.Join(StreamFileDir, name)
+}
+
+// Stream names are passed as a comma separated list
+func (backup MultiStreamBackupOperation) MakeArgs() []string {
+	return []string{
+		OpMultiStreamBackup,
+		StreamFileDir,
+		strings.Join(backup.Streams, ","),
+		backup.Tag,
+	}
+}
+
+func (restore MultiStreamRestoreOperation) MakeArgs() []string {
 	return []string{
+		OpMultiStreamRestore,
+		StreamFileDir,
+		restore.BackupID,
+		strings.Join(restore.Streams, ","),
+	}
+}
+
+func (backup FileSystemBackupOperation) MakeArgs() []string {
+	args := []string{
 		OpFsBackup,
 		backup.Path,
 		backup.Tag,
 	}
+	return append(args, metadataArgs(backup.Metadata)...)
+}
+
+// metadataArgs are passed as --<name>=<value> options after the positional arguments
+func metadataArgs(metadata api.BackupMetadata) []string {
+	args := []string{}
+	if metadata.ParentSnapshotID != "" {
+		args = append(args, "--parent="+metadata.ParentSnapshotID)
+	}
+	if metadata.Source != "" {
+		args = append(args, "--source="+metadata.Source)
+	}
+	if metadata.RetentionHint != "" {
+		args = append(args, "--retention="+metadata.RetentionHint)
+	}
+	for _, key := range sortedNames(metadata.Labels) {
+		args = append(args, "--label="+key+"="+metadata.Labels[key])
+	}
+	return args
 }
 
 func (restore FileSystemRestoreOperation) MakeArgs() []string {
-	return []string{
+	// Empty path would be dropped by the implementation before the options
+	restorePath := restore.Path
+	if restorePath == "" {
+		restorePath = "/"
+	}
+	args := []string{
 		OpFsRestore,
-		restore.Path,
+		restorePath,
+		restore.BackupID,
+	}
+	for _, include := range restore.Include {
+		args = append(args, "--include="+include)
+	}
+	for _, exclude := range restore.Exclude {
+		args = append(args, "--exclude="+exclude)
+	}
+	if restore.OverwritePolicy != "" {
+		args = append(args, "--overwrite="+strings.ToLower(string(restore.OverwritePolicy)))
+	}
+	if restore.SparseRestore {
+		args = append(args, "--sparse")
+	}
+	return args
+}
+
+// Volume names are passed as a comma separated list, the implementation
+// should back up and restore each volume from /mnt/data/<name>
+func (backup MultiVolumeBackupOperation) MakeArgs() []string {
+	return []string{
+		OpMultiVolumeBackup,
+		volumeNamesArg(backup.Volumes),
+		backup.Tag,
+	}
+}
+
+func (restore MultiVolumeRestoreOperation) MakeArgs() []string {
+	return []string{
+		OpMultiVolumeRestore,
+		restore.BackupID,
+		volumeNamesArg(restore.Volumes),
+	}
+}
+
+func (backup BlockBackupOperation) MakeArgs() []string {
+	return []string{
+		OpBlockBackup,
+		BlockDevicePath,
+		backup.Tag,
+	}
+}
+
+func (restore BlockRestoreOperation) MakeArgs() []string {
+	return []string{
+		OpBlockRestore,
+		BlockDevicePath,
 		restore.BackupID,
+		strconv.FormatBool(restore.SparseRestore),
 	}
 }
 
+func volumeNamesArg(volumes map[string]string) string {
+	return strings.Join(sortedNames(volumes), ",")
+}
+
+func sortedNames(volumes map[string]string) []string {
+	names := make([]string, 0, len(volumes))
+	for name := range volumes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
 func (sidecar FileSystemSidecarOperation) MakeArgs() []string {
+	fileName := sidecar.FileName
+	if fileName == "" {
+		fileName = SidecarCommandFile
+	}
 	return []string{
 		OpFsSidecar,
-		sidecar.FileName,
+		fileName,
+		sidecar.mountPath(),
 	}
 }
 
@@ -222,6 +579,22 @@ func (streamRestore StreamRestoreOperation) MakeContainers() []corev1.Container
 	}
 }
 
+func (backup MultiStreamBackupOperation) MakeContainers() []corev1.Container {
+	containers := []corev1.Container{}
+	for _, generator := range backup.StreamGenerators {
+		containers = append(containers, appendStreamFileMount(generator))
+	}
+	return containers
+}
+
+func (restore MultiStreamRestoreOperation) MakeContainers() []corev1.Container {
+	containers := []corev1.Container{}
+	for _, ingestor := range restore.StreamIngestors {
+		containers = append(containers, appendStreamFileMount(ingestor))
+	}
+	return containers
+}
+
 func appendStreamFileMount(container corev1.Container) corev1.Container {
 	streamFileMount := streamVolumeMount()
 	container.VolumeMounts = append(container.VolumeMounts, streamFileMount)
@@ -231,24 +604,53 @@ func (fsBackup FileSystemBackupOperation) MakeContainers() []corev1.Container
 func (fsRestore FileSystemRestoreOperation) MakeContainers() []corev1.Container { return nil }
 func (sidecar FileSystemSidecarOperation) MakeContainers() []corev1.Container   { return nil }
 
+func (backup MultiVolumeBackupOperation) MakeContainers() []corev1.Container       { return nil }
+func (restore MultiVolumeRestoreOperation) MakeContainers() []corev1.Container     { return nil }
+func (backup MultiVolumeBackupOperation) MakeInitContainers() []corev1.Container   { return nil }
+func (restore MultiVolumeRestoreOperation) MakeInitContainers() []corev1.Container { return nil }
+
+func (browse BrowseServerOperation) MakeContainers() []corev1.Container     { return nil }
+func (browse BrowseServerOperation) MakeInitContainers() []corev1.Container { return nil }
+
+func (backup BlockBackupOperation) MakeContainers() []corev1.Container       { return nil }
+func (restore BlockRestoreOperation) MakeContainers() []corev1.Container     { return nil }
+func (backup BlockBackupOperation) MakeInitContainers() []corev1.Container   { return nil }
+func (restore BlockRestoreOperation) MakeInitContainers() []corev1.Container { return nil }
+
 func (streamBackup StreamBackupOperation) MakeInitContainers() []corev1.Container {
 	initImage := streamBackup.InitImage
-	return []corev1.Container{streamInitContainer(initImage)}
+	return []corev1.Container{streamInitContainer(initImage, streamFileName())}
 }
 
 func (streamRestore StreamRestoreOperation) MakeInitContainers() []corev1.Container {
 	initImage := streamRestore.InitImage
-	return []corev1.Container{streamInitContainer(initImage)}
+	return []corev1.Container{streamInitContainer(initImage, streamFileName())}
+}
+
+func (backup MultiStreamBackupOperation) MakeInitContainers() []corev1.Container {
+	return []corev1.Container{streamInitContainer(backup.InitImage, multiStreamFileNames(backup.Streams)...)}
+}
+
+func (restore MultiStreamRestoreOperation) MakeInitContainers() []corev1.Container {
+	return []corev1.Container{streamInitContainer(restore.InitImage, multiStreamFileNames(restore.Streams)...)}
+}
+
+func multiStreamFileNames(streams []string) []string {
+	fileNames := []string{}
+	for _, stream := range streams {
+		fileNames = append(fileNames, MultiStreamFileName(stream))
+	}
+	return fileNames
 }
 
 func (fsBackup FileSystemBackupOperation) MakeInitContainers() []corev1.Container   { return nil }
 func (fsRestore FileSystemRestoreOperation) MakeInitContainers() []corev1.Container { return nil }
 func (sidecar FileSystemSidecarOperation) MakeInitContainers() []corev1.Container   { return nil }
 
-func streamInitContainer(initImage string) corev1.Container {
+func streamInitContainer(initImage string, fileNames ...string) corev1.Container {
 	return corev1.Container{
-		Name:         "initstreamfile",
-		Command:      []string{"mkfifo", streamFileName()},
+		Name:         StreamInitContainerName,
+		Command:      append([]string{"mkfifo"}, fileNames...),
 		Image:        streamInitImage(initImage),
 		VolumeMounts: []corev1.VolumeMount{streamVolumeMount()},
 	}
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"testing"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMultiVolumeBackupOperation(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	operation := MultiVolumeBackupOperation{
+		Volumes:       map[string]string{"wal": "db-wal", "data": "db-data"},
+		Tag:           "db",
+		ReadOnlyMount: true,
+	}
+
+	volumes, mounts, _ := operation.MakeVolumes()
+	matcher.Expect(volumes).To(gomega.HaveLen(2))
+	matcher.Expect(volumes[0].Name).To(gomega.Equal("data"))
+	matcher.Expect(volumes[0].PersistentVolumeClaim.ClaimName).To(gomega.Equal("db-data"))
+	matcher.Expect(volumes[0].PersistentVolumeClaim.ReadOnly).To(gomega.BeTrue())
+	matcher.Expect(mounts[1].MountPath).To(gomega.Equal("/mnt/data/wal"))
+	matcher.Expect(operation.MakeArgs()).To(gomega.Equal([]string{OpMultiVolumeBackup, "data,wal", "db"}))
+}
+
+func TestMultiVolumeRestoreOperation(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	operation := MultiVolumeRestoreOperation{
+		Volumes:  map[string]string{"wal": "new-wal"},
+		BackupID: "k123",
+	}
+
+	volumes, mounts, _ := operation.MakeVolumes()
+	matcher.Expect(volumes).To(gomega.HaveLen(1))
+	matcher.Expect(volumes[0].PersistentVolumeClaim.ClaimName).To(gomega.Equal("new-wal"))
+	matcher.Expect(mounts[0].MountPath).To(gomega.Equal("/mnt/data/wal"))
+	matcher.Expect(operation.MakeArgs()).To(gomega.Equal([]string{OpMultiVolumeRestore, "k123", "wal"}))
+}
+
+func TestOperationFromSpecMultiVolume(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	spec := api.DatamoverOperationSpec{
+		Type: api.OperationTypeMultiVolumeBackup,
+		MultiVolumeBackup: &api.MultiVolumeBackupParams{
+			Volumes: map[string]string{"data": "db-data"},
+		},
+	}
+	operation, err := OperationFromSpec(spec)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(operation).To(gomega.BeAssignableToTypeOf(MultiVolumeBackupOperation{}))
+
+	spec.MultiVolumeBackup.Volumes = map[string]string{"Data/WAL": "db-wal"}
+	_, err = OperationFromSpec(spec)
+	matcher.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("Invalid volume name")))
+
+	spec.MultiVolumeBackup.Volumes = nil
+	_, err = OperationFromSpec(spec)
+	matcher.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestBlockOperations(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+
+	backup := BlockBackupOperation{PVC: "disk", Tag: "vm"}
+	volumes, mounts, devices := backup.MakeVolumes()
+	matcher.Expect(volumes).To(gomega.HaveLen(1))
+	matcher.Expect(volumes[0].PersistentVolumeClaim.ClaimName).To(gomega.Equal("disk"))
+	matcher.Expect(volumes[0].PersistentVolumeClaim.ReadOnly).To(gomega.BeTrue())
+	matcher.Expect(mounts).To(gomega.BeEmpty())
+	matcher.Expect(devices).To(gomega.HaveLen(1))
+	matcher.Expect(devices[0].Name).To(gomega.Equal(volumes[0].Name))
+	matcher.Expect(devices[0].DevicePath).To(gomega.Equal(BlockDevicePath))
+	matcher.Expect(backup.MakeArgs()).To(gomega.Equal([]string{OpBlockBackup, BlockDevicePath, "vm"}))
+
+	restore := BlockRestoreOperation{PVC: "new-disk", BackupID: "k123", SparseRestore: true}
+	volumes, _, devices = restore.MakeVolumes()
+	matcher.Expect(volumes[0].PersistentVolumeClaim.ReadOnly).To(gomega.BeFalse())
+	matcher.Expect(devices).To(gomega.HaveLen(1))
+	matcher.Expect(restore.MakeArgs()).To(gomega.Equal([]string{OpBlockRestore, BlockDevicePath, "k123", "true"}))
+}
+
+func TestBackupMetadataArgs(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	operation := FileSystemBackupOperation{
+		Path: "/",
+		Metadata: api.BackupMetadata{
+			ParentSnapshotID: "k123",
+			Source:           "ns/data",
+			RetentionHint:    "daily",
+			Labels:           map[string]string{"app": "db", "cluster": "prod"},
+		},
+	}
+	matcher.Expect(operation.MakeArgs()).To(gomega.Equal([]string{
+		OpFsBackup, "/", "",
+		"--parent=k123", "--source=ns/data", "--retention=daily", "--label=app=db", "--label=cluster=prod",
+	}))
+
+	stream := StreamBackupOperation{BackupObjectName: "dump", Tag: "db:pg"}
+	matcher.Expect(stream.MakeArgs()).To(gomega.Equal([]string{OpStreamBackup, streamFileName(), "dump", "db:pg"}))
+}
+
+func TestOperationFromSpecBackupMetadata(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	spec := api.DatamoverOperationSpec{
+		Type: api.OperationTypeFsBackup,
+		FsBackup: &api.FsBackupParams{
+			PVC:      "data",
+			Metadata: &api.BackupMetadata{Labels: map[string]string{"app": "db"}},
+		},
+	}
+	operation, err := OperationFromSpec(spec)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(operation.(FileSystemBackupOperation).Metadata.Labels).To(gomega.HaveKeyWithValue("app", "db"))
+
+	spec.FsBackup.Metadata.Labels = map[string]string{"app": "a:b"}
+	_, err = OperationFromSpec(spec)
+	matcher.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("Invalid backup metadata label")))
+}
+
+func TestClientArgsFromSnapshotBackup(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	dmOperation := api.DatamoverOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "ns"},
+		Spec: api.DatamoverOperationSpec{
+			Type:           api.OperationTypeSnapshotBackup,
+			SnapshotBackup: &api.SnapshotBackupParams{PVC: "data", Path: "/"},
+		},
+	}
+	args, err := ClientArgsFromOperation(dmOperation)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	operation := args.Operation.(FileSystemBackupOperation)
+	matcher.Expect(operation.PVC).To(gomega.Equal("backup-snapshot"))
+	matcher.Expect(operation.Metadata.Source).To(gomega.Equal("ns/data"))
+}
+
+func TestFileSystemRestoreArgs(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	operation := FileSystemRestoreOperation{BackupID: "k123", PVC: "data"}
+	matcher.Expect(operation.MakeArgs()).To(gomega.Equal([]string{OpFsRestore, "/", "k123"}))
+
+	operation = FileSystemRestoreOperation{
+		Path:            "/restore",
+		BackupID:        "k123",
+		Include:         []string{"db", "etc/config.yaml"},
+		Exclude:         []string{"db/tmp"},
+		OverwritePolicy: api.OverwritePolicyRename,
+		SparseRestore:   true,
+	}
+	matcher.Expect(operation.MakeArgs()).To(gomega.Equal([]string{
+		OpFsRestore, "/restore", "k123",
+		"--include=db", "--include=etc/config.yaml", "--exclude=db/tmp", "--overwrite=rename", "--sparse",
+	}))
+}
+
+func TestOperationFromSpecRestorePaths(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	spec := api.DatamoverOperationSpec{
+		Type: api.OperationTypeFsRestore,
+		FsRestore: &api.FsRestoreParams{
+			PVC:             "data",
+			BackupID:        "k123",
+			Include:         []string{"db"},
+			Exclude:         []string{"db/tmp"},
+			OverwritePolicy: api.OverwritePolicySkip,
+		},
+	}
+	operation, err := OperationFromSpec(spec)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	restore := operation.(FileSystemRestoreOperation)
+	matcher.Expect(restore.Include).To(gomega.Equal([]string{"db"}))
+	matcher.Expect(restore.OverwritePolicy).To(gomega.Equal(api.OverwritePolicySkip))
+
+	for _, invalid := range []string{"/db", "../db", "db/../..", "my db", ""} {
+		spec.FsRestore.Exclude = []string{invalid}
+		_, err = OperationFromSpec(spec)
+		matcher.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("Invalid restore path")), invalid)
+	}
+}
+
+func TestMultiStreamOperations(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	generators := []corev1.Container{{Name: "dump-db1"}, {Name: "dump-db2"}}
+	backup := MultiStreamBackupOperation{
+		Streams:          []string{"db1.sql", "db2.sql"},
+		StreamGenerators: generators,
+		Tag:              "app:db",
+	}
+	matcher.Expect(backup.MakeArgs()).To(gomega.Equal([]string{OpMultiStreamBackup, StreamFileDir, "db1.sql,db2.sql", "app:db"}))
+	initContainers := backup.MakeInitContainers()
+	matcher.Expect(initContainers).To(gomega.HaveLen(1))
+	matcher.Expect(initContainers[0].Command).To(gomega.Equal([]string{"mkfifo", "/tmp/stream_file/db1.sql", "/tmp/stream_file/db2.sql"}))
+	containers := backup.MakeContainers()
+	matcher.Expect(containers).To(gomega.HaveLen(2))
+	for _, container := range containers {
+		matcher.Expect(container.VolumeMounts).To(gomega.ContainElement(streamVolumeMount()))
+	}
+
+	restore := MultiStreamRestoreOperation{Streams: []string{"db2.sql"}, BackupID: "b123"}
+	matcher.Expect(restore.MakeArgs()).To(gomega.Equal([]string{OpMultiStreamRestore, StreamFileDir, "b123", "db2.sql"}))
+}
+
+func TestOperationFromSpecMultiStream(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	spec := api.DatamoverOperationSpec{
+		Type: api.OperationTypeMultiStreamRestore,
+		MultiStreamRestore: &api.MultiStreamRestoreParams{
+			Streams:         []string{"db1.sql"},
+			StreamIngestors: []corev1.Container{{Name: "load"}},
+			BackupID:        "b123",
+		},
+	}
+	operation, err := OperationFromSpec(spec)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(operation.(MultiStreamRestoreOperation).StreamIngestors).To(gomega.HaveLen(1))
+
+	spec.MultiStreamRestore.Streams = []string{"db1.sql", "db1.sql"}
+	_, err = OperationFromSpec(spec)
+	matcher.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("Duplicate stream name")))
+	spec.MultiStreamRestore.Streams = []string{"a,b"}
+	_, err = OperationFromSpec(spec)
+	matcher.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("Invalid stream name")))
+}
+
+func TestStreamEncryptionAndChecksum(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	encryption := &api.StreamEncryption{SecretName: "stream-key"}
+	backup := StreamBackupOperation{
+		BackupObjectName: "dump",
+		Compression:      "zstd",
+		Encryption:       encryption,
+	}
+	matcher.Expect(backup.MakeArgs()).To(gomega.Equal([]string{
+		OpStreamBackup, streamFileName(), "dump", "",
+		"--compression=zstd", "--encryption-key=" + EncryptionKeyFile,
+	}))
+	volumes, volumeMounts, _ := backup.MakeVolumes()
+	matcher.Expect(volumes).To(gomega.ContainElement(gomega.HaveField("VolumeSource.Secret.Items", []corev1.KeyToPath{{Key: "key", Path: "key"}})))
+	matcher.Expect(volumeMounts).To(gomega.ContainElement(gomega.HaveField("MountPath", "/etc/stream-encryption")))
+
+	restore := StreamRestoreOperation{
+		BackupObjectName: "dump",
+		BackupID:         "k123",
+		Encryption:       encryption,
+		Checksum:         "sha256:abc",
+	}
+	matcher.Expect(restore.MakeArgs()).To(gomega.Equal([]string{
+		OpStreamRestore, streamFileName(), "dump", "k123",
+		"--encryption-key=" + EncryptionKeyFile, "--checksum=sha256:abc", "--buffer-dir=" + StreamBufferDir,
+	}))
+	volumes, _, _ = restore.MakeVolumes()
+	matcher.Expect(volumes).To(gomega.ContainElement(gomega.HaveField("Name", "stream-buffer")))
+
+	// Encrypted data is verified before it's written to the stream
+	restore.Checksum = ""
+	matcher.Expect(restore.MakeArgs()).To(gomega.ContainElement("--buffer-dir=" + StreamBufferDir))
+	volumes, _, _ = restore.MakeVolumes()
+	matcher.Expect(volumes).To(gomega.ContainElement(gomega.HaveField("Name", "stream-buffer")))
+
+	restore.Encryption = nil
+	volumes, _, _ = restore.MakeVolumes()
+	matcher.Expect(volumes).ToNot(gomega.ContainElement(gomega.HaveField("Name", "stream-buffer")))
+}
+
+func TestOperationFromSpecStreamEncryption(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	spec := api.DatamoverOperationSpec{
+		Type: api.OperationTypeStreamRestore,
+		StreamRestore: &api.StreamRestoreParams{
+			BackupID:   "k123",
+			Encryption: &api.StreamEncryption{SecretName: "stream-key", Key: "data-key"},
+			Checksum:   "sha256:abc",
+		},
+	}
+	operation, err := OperationFromSpec(spec)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	restore := operation.(StreamRestoreOperation)
+	matcher.Expect(restore.Encryption.Key).To(gomega.Equal("data-key"))
+	matcher.Expect(restore.Checksum).To(gomega.Equal("sha256:abc"))
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ProgressContainerName is the sidecar printing progress reported by the client to its logs
+	ProgressContainerName = "progress"
+	// ProgressFileEnv is set to the file the client periodically writes JSON encoded api.OperationProgress to
+	ProgressFileEnv = "PROGRESS_FILE"
+
+	progressVolumeName   = "progress"
+	progressMountPath    = "/etc/progress"
+	progressFile         = progressMountPath + "/progress.json"
+	progressDefaultImage = "busybox:1.36.1"
+	progressLogPrefix    = "progress: "
+	// Interval to retry streaming progress logs until the sidecar starts
+	progressRetryInterval = time.Second * 2
+)
+
+// ErrSidecarsNotSupported is returned when the API server dropped the restart policy of the progress sidecar
+var ErrSidecarsNotSupported = errors.New("Progress requires native sidecar containers, supported by Kubernetes 1.29+")
+
+// CheckProgressSidecar checks that the created pod spec kept the restart policy of the progress sidecar.
+// API servers without native sidecar containers drop it, the sidecar then runs as an init container
+// which never exits and the client pod never starts.
+func CheckProgressSidecar(spec corev1.PodSpec) error {
+	for _, container := range spec.InitContainers {
+		if container.Name == ProgressContainerName && container.RestartPolicy == nil {
+			return ErrSidecarsNotSupported
+		}
+	}
+	return nil
+}
+
+// makeProgressSidecar creates the sidecar printing the progress file to its logs when it changes.
+// Sidecar is a native sidecar container, which requires Kubernetes 1.29+.
+// Sidecar exits on SIGTERM so it does not delay the pod termination.
+func makeProgressSidecar(interval time.Duration, image string) corev1.Container {
+	restartAlways := corev1.ContainerRestartPolicyAlways
+	seconds := max(int64(interval.Seconds()), 1)
+	if image == "" {
+		image = progressDefaultImage
+	}
+	return corev1.Container{
+		Name:  ProgressContainerName,
+		Image: image,
+		Command: []string{
+			"sh",
+			"-c",
+			"trap 'exit 0' TERM; last='';" +
+				" while true; do" +
+				fmt.Sprintf(" current=$(cat %s 2>/dev/null);", progressFile) +
+				" if [ -n \"$current\" ] && [ \"$current\" != \"$last\" ];" +
+				fmt.Sprintf(" then echo \"%s$current\"; last=$current; fi;", progressLogPrefix) +
+				fmt.Sprintf(" sleep %d & wait $!;", seconds) +
+				" done"},
+		VolumeMounts:  []corev1.VolumeMount{progressVolumeMount()},
+		RestartPolicy: &restartAlways,
+	}
+}
+
+func makeProgressVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: progressVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				Medium: corev1.StorageMediumMemory,
+			},
+		},
+	}
+}
+
+func progressVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      progressVolumeName,
+		MountPath: progressMountPath,
+	}
+}
+
+func progressEnv() corev1.EnvVar {
+	return corev1.EnvVar{Name: ProgressFileEnv, Value: progressFile}
+}
+
+// GetProgress returns the last progress reported by the client pod.
+// Returns nil if the client did not report progress yet.
+func GetProgress(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod) (*api.OperationProgress, error) {
+	logs, err := GetLogTail(ctx, cli, pod, ProgressContainerName, 1)
+	if err != nil {
+		return nil, err
+	}
+	progress, ok := ParseProgressLine(strings.TrimSpace(logs))
+	if !ok {
+		return nil, nil
+	}
+	return progress, nil
+}
+
+// WatchProgress sends progress reported by the client pod to the returned channel.
+// The channel is closed when the pod finishes or ctx is cancelled.
+// Client pod should be created with CreateClientArgs.ProgressInterval set.
+func WatchProgress(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod) <-chan api.OperationProgress {
+	progressCh := make(chan api.OperationProgress)
+	go func() {
+		defer close(progressCh)
+		for {
+			err := watchProgressLogs(ctx, cli, pod, progressCh)
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+			// Sidecar may not be started yet
+			log.Log.Info("Unable to stream client progress, retrying", "pod", pod.Name, "error", err.Error())
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(progressRetryInterval):
+			}
+			current, err := cli.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			if err != nil || podFinished(*current) {
+				return
+			}
+		}
+	}()
+	return progressCh
+}
+
+func watchProgressLogs(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod, progressCh chan<- api.OperationProgress) error {
+	stream, err := cli.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: ProgressContainerName,
+		Follow:    true,
+	}).Stream(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Unable to stream client progress")
+	}
+	defer stream.Close() //nolint:errcheck
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		progress, ok := ParseProgressLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		select {
+		case progressCh <- *progress:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// ParseProgressLine parses a progress line printed by the progress sidecar
+func ParseProgressLine(line string) (*api.OperationProgress, bool) {
+	data, found := strings.CutPrefix(line, progressLogPrefix)
+	if !found {
+		return nil, false
+	}
+	progress := &api.OperationProgress{}
+	if err := json.Unmarshal([]byte(data), progress); err != nil {
+		return nil, false
+	}
+	return progress, true
+}
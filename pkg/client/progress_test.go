@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kanisterio/datamover/pkg/session"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+
+	progress, ok := ParseProgressLine(`progress: {"bytesHashed":2200,"bytesUploaded":1000,"filesProcessed":23,"totalBytes":10000,"eta":"1m30s"}`)
+	matcher.Expect(ok).To(gomega.BeTrue())
+	matcher.Expect(progress.BytesHashed).To(gomega.BeEquivalentTo(2200))
+	matcher.Expect(progress.BytesUploaded).To(gomega.BeEquivalentTo(1000))
+	matcher.Expect(progress.FilesProcessed).To(gomega.BeEquivalentTo(23))
+	matcher.Expect(progress.TotalBytes).To(gomega.BeEquivalentTo(10000))
+	matcher.Expect(progress.ETA.Duration).To(gomega.Equal(90 * time.Second))
+
+	_, ok = ParseProgressLine("fake logs")
+	matcher.Expect(ok).To(gomega.BeFalse())
+	_, ok = ParseProgressLine("progress: {")
+	matcher.Expect(ok).To(gomega.BeFalse())
+}
+
+func TestMakeClientPodProgress(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	args := makeJobArgs()
+
+	pod, err := MakeClientPod(args, session.SessionConfig{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(pod.Spec.InitContainers).To(gomega.BeEmpty())
+
+	args.ProgressInterval = 10 * time.Second
+	pod, err = MakeClientPod(args, session.SessionConfig{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(pod.Spec.InitContainers).To(gomega.HaveLen(1))
+	sidecar := pod.Spec.InitContainers[0]
+	matcher.Expect(sidecar.Name).To(gomega.Equal(ProgressContainerName))
+	matcher.Expect(sidecar.Image).To(gomega.Equal(progressDefaultImage))
+	matcher.Expect(*sidecar.RestartPolicy).To(gomega.Equal(corev1.ContainerRestartPolicyAlways))
+	matcher.Expect(sidecar.Command[2]).To(gomega.ContainSubstring("sleep 10"))
+	matcher.Expect(pod.Spec.Containers[0].Env).To(gomega.ContainElement(corev1.EnvVar{Name: ProgressFileEnv, Value: progressFile}))
+	matcher.Expect(pod.Spec.Containers[0].VolumeMounts).To(gomega.ContainElement(progressVolumeMount()))
+
+	args.ProgressImage = "registry.local/busybox:1.36.1"
+	pod, err = MakeClientPod(args, session.SessionConfig{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(pod.Spec.InitContainers[0].Image).To(gomega.Equal("registry.local/busybox:1.36.1"))
+}
+
+func TestCheckProgressSidecar(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	args := makeJobArgs()
+	pod, err := MakeClientPod(args, session.SessionConfig{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(CheckProgressSidecar(pod.Spec)).To(gomega.Succeed())
+
+	args.ProgressInterval = 10 * time.Second
+	pod, err = MakeClientPod(args, session.SessionConfig{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(CheckProgressSidecar(pod.Spec)).To(gomega.Succeed())
+
+	// Dropped by API servers without native sidecars
+	pod.Spec.InitContainers[0].RestartPolicy = nil
+	matcher.Expect(CheckProgressSidecar(pod.Spec)).To(gomega.MatchError(ErrSidecarsNotSupported))
+}
+
+func TestGetProgressNotReported(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	pod := makeRunningPod()
+	cli := kubefake.NewSimpleClientset(pod)
+
+	progress, err := GetProgress(context.Background(), cli, pod)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(progress).To(gomega.BeNil())
+}
+
+func TestClassifyFailureIgnoresProgressSidecar(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	pod := corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed}}
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{terminatedStatus(ProgressContainerName, 137, "")}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{terminatedStatus(MainContainerName, 1, `{"errors":["Failed to create snapshot"]}`)}
+
+	podErr := ClassifyFailure(pod)
+	matcher.Expect(podErr.Type).To(gomega.Equal(FailureMainContainer))
+	matcher.Expect(podErr.Message).To(gomega.Equal("Failed to create snapshot"))
+}
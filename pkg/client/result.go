@@ -0,0 +1,69 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ResultPath is the file the client writes JSON encoded api.OperationResult to
+const ResultPath = corev1.TerminationMessagePathDefault
+
+// ParseResult reads the operation result from the main container termination message.
+// Returns empty result if the client does not report one.
+func ParseResult(pod corev1.Pod) (*api.OperationResult, error) {
+	terminated := MainContainerTerminated(pod)
+	if terminated == nil {
+		return nil, errors.New("Main container is not terminated")
+	}
+	return parseResultMessage(terminated.Message)
+}
+
+// FailedResult returns the result of a failed pod. If the client did not report
+// a result, errors contain the termination message or the pod failure reason.
+func FailedResult(pod corev1.Pod) *api.OperationResult {
+	if terminated := MainContainerTerminated(pod); terminated != nil {
+		result, err := parseResultMessage(terminated.Message)
+		if err == nil && len(result.Errors) > 0 {
+			return result
+		}
+		// Termination message falls back to logs if the client failed without reporting a result
+		if message := strings.TrimSpace(terminated.Message); err != nil && message != "" {
+			return &api.OperationResult{Errors: []string{message}}
+		}
+		if terminated.Reason != "" {
+			return &api.OperationResult{Errors: []string{terminated.Reason}}
+		}
+	}
+	reason := pod.Status.Message
+	if reason == "" {
+		reason = pod.Status.Reason
+	}
+	return &api.OperationResult{Errors: []string{reason}}
+}
+
+// MainContainerTerminated returns terminated state of the main container or nil if it is still running
+func MainContainerTerminated(pod corev1.Pod) *corev1.ContainerStateTerminated {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == MainContainerName {
+			return status.State.Terminated
+		}
+	}
+	return nil
+}
+
+func parseResultMessage(message string) (*api.OperationResult, error) {
+	result := &api.OperationResult{}
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return result, nil
+	}
+	if err := json.Unmarshal([]byte(message), result); err != nil {
+		return nil, errors.Wrap(err, "Unable to parse operation result")
+	}
+	return result, nil
+}
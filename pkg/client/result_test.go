@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func makeFinishedPod(phase corev1.PodPhase, message string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "client", Namespace: "ns"},
+		Status: corev1.PodStatus{
+			Phase: phase,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: MainContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Message: message,
+				}},
+			}},
+		},
+	}
+}
+
+func TestWaitForClientPodResult(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	pod := makeFinishedPod(corev1.PodSucceeded, `{"snapshotID":"k123","sizeBytes":2048,"files":10,"directories":2,"duration":"1m30s","checksum":"sha256:abc"}`)
+	cli := kubefake.NewSimpleClientset(pod)
+
+	result, err := WaitForClientPod(context.Background(), cli, pod)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(result.SnapshotID).To(gomega.Equal("k123"))
+	matcher.Expect(result.SizeBytes).To(gomega.BeEquivalentTo(2048))
+	matcher.Expect(result.Files).To(gomega.BeEquivalentTo(10))
+	matcher.Expect(result.Directories).To(gomega.BeEquivalentTo(2))
+	matcher.Expect(result.Duration.Duration).To(gomega.Equal(90 * time.Second))
+	matcher.Expect(result.Checksum).To(gomega.Equal("sha256:abc"))
+}
+
+func TestWaitForClientPodFailed(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	pod := makeFinishedPod(corev1.PodFailed, `{"errors":["Failed to connect to repository"]}`)
+	cli := kubefake.NewSimpleClientset(pod)
+
+	result, err := WaitForClientPod(context.Background(), cli, pod)
+	matcher.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("Failed to connect to repository")))
+	matcher.Expect(result.Errors).To(gomega.ConsistOf("Failed to connect to repository"))
+}
+
+func TestFailedResultFallsBackToLogs(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	pod := makeFinishedPod(corev1.PodFailed, "kopia: command not found\n")
+	matcher.Expect(FailedResult(*pod).Errors).To(gomega.ConsistOf("kopia: command not found"))
+}
+
+func TestParseResultInvalid(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	_, err := ParseResult(*makeFinishedPod(corev1.PodSucceeded, "not json"))
+	matcher.Expect(err).To(gomega.HaveOccurred())
+
+	// Clients which do not report results return empty result
+	result, err := ParseResult(*makeFinishedPod(corev1.PodSucceeded, ""))
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(result.SnapshotID).To(gomega.BeEmpty())
+}
+
+func TestParseMultiStreamResult(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	result, err := ParseResult(*makeFinishedPod(corev1.PodSucceeded,
+		`{"snapshotID":"","backupID":"b123","streams":[{"name":"db1.sql","snapshotID":"k1"},{"name":"db2.sql","snapshotID":"k2"}],"sizeBytes":9,"files":2}`))
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(result.SnapshotID).To(gomega.BeEmpty())
+	matcher.Expect(result.BackupID).To(gomega.Equal("b123"))
+	matcher.Expect(result.Streams).To(gomega.Equal([]api.StreamSnapshot{
+		{Name: "db1.sql", SnapshotID: "k1"},
+		{Name: "db2.sql", SnapshotID: "k2"},
+	}))
+}
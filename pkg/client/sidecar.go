@@ -0,0 +1,193 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/kanisterio/datamover/pkg/session"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// SidecarContainerName is the datamover client container injected into workload pods
+	SidecarContainerName = "datamover"
+	// SidecarCommandFile is the default named pipe the sidecar reads commands from
+	SidecarCommandFile = "/tmp/datamover-commands"
+
+	sidecarDataMountPath = "/mnt/data/data"
+	sidecarResultPrefix  = "result: "
+)
+
+// SidecarCommandType is the operation run by the sidecar
+type SidecarCommandType string
+
+const (
+	SidecarBackup  SidecarCommandType = "backup"
+	SidecarRestore SidecarCommandType = "restore"
+)
+
+// SidecarCommand is written to the sidecar command file as a line:
+//
+//	backup <id> <path> [tag]
+//	restore <id> <path> <backup id>
+//
+// Path is relative to the sidecar volume mount. The sidecar prints the result as
+// "result: <id> <JSON encoded api.OperationResult>" to its logs.
+type SidecarCommand struct {
+	Type SidecarCommandType
+	// Unique ID to match the result with the command
+	ID       string
+	Path     string
+	Tag      string
+	BackupID string
+}
+
+func (command SidecarCommand) Validate() error {
+	fields := []string{command.ID, command.Path, command.Tag, command.BackupID}
+	if slices.ContainsFunc(fields, func(field string) bool { return strings.ContainsAny(field, " \t\n") }) {
+		return errors.New("Sidecar command fields cannot contain whitespace")
+	}
+	if command.ID == "" || command.Path == "" {
+		return errors.New("Sidecar command requires ID and path")
+	}
+	switch command.Type {
+	case SidecarBackup:
+		return nil
+	case SidecarRestore:
+		if command.BackupID == "" {
+			return errors.New("Sidecar restore command requires backup ID")
+		}
+		return nil
+	default:
+		return fmt.Errorf("Unsupported sidecar command: %s", command.Type)
+	}
+}
+
+// String returns the command line to write to the sidecar command file
+func (command SidecarCommand) String() string {
+	last := command.Tag
+	if command.Type == SidecarRestore {
+		last = command.BackupID
+	}
+	return strings.TrimSpace(strings.Join([]string{string(command.Type), command.ID, command.Path, last}, " "))
+}
+
+// ExecCommand returns the command to exec in the sidecar container to send the command.
+// Command line is passed as an argument, so it's not interpreted by the shell.
+func (command SidecarCommand) ExecCommand(commandFile string) ([]string, error) {
+	if err := command.Validate(); err != nil {
+		return nil, err
+	}
+	if commandFile == "" {
+		commandFile = SidecarCommandFile
+	}
+	return []string{"sh", "-c", `echo "$0" > "$1"`, command.String(), commandFile}, nil
+}
+
+// InjectSidecarWithClient injects the sidecar using config of the session from clientArgs
+func InjectSidecarWithClient(
+	ctx context.Context,
+	sessionCli session.Client,
+	template *corev1.PodTemplateSpec,
+	clientArgs CreateClientArgs,
+) error {
+	sessionConfig, err := session.GetConfigWithClient(ctx, sessionCli, clientArgs.SessionName, clientArgs.SessionNamespace)
+	if err != nil {
+		return errors.Wrap(err, "Cannot extract datamover session config")
+	}
+	return InjectSidecar(template, clientArgs, *sessionConfig)
+}
+
+// InjectSidecar adds the datamover client container running FileSystemSidecarOperation
+// to a workload pod template, e.g. of a Deployment or StatefulSet.
+// Operation volume mount should reference a volume of the pod template.
+// Pod level options and progress reporting of clientArgs are not applied to the workload.
+func InjectSidecar(template *corev1.PodTemplateSpec, clientArgs CreateClientArgs, sessionConfig session.SessionConfig) error {
+	operation, ok := clientArgs.Operation.(FileSystemSidecarOperation)
+	if !ok {
+		return errors.New("Sidecar requires FileSystemSidecarOperation")
+	}
+	if !slices.ContainsFunc(template.Spec.Volumes, func(volume corev1.Volume) bool {
+		return volume.Name == operation.VolumeMount.Name
+	}) {
+		return fmt.Errorf("Volume %s not found in pod template", operation.VolumeMount.Name)
+	}
+	if slices.ContainsFunc(template.Spec.Containers, func(container corev1.Container) bool {
+		return container.Name == SidecarContainerName
+	}) {
+		return errors.New("Sidecar is already injected")
+	}
+
+	clientArgs.ProgressInterval = 0
+	pod, err := MakeClientPod(clientArgs, sessionConfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to generate sidecar spec")
+	}
+	if err := validateVolumeNames([][]corev1.Volume{template.Spec.Volumes, pod.Spec.Volumes}); err != nil {
+		return err
+	}
+	sidecar := pod.Spec.Containers[0]
+	sidecar.Name = SidecarContainerName
+
+	template.Spec.Volumes = append(template.Spec.Volumes, pod.Spec.Volumes...)
+	template.Spec.Containers = append(template.Spec.Containers, sidecar)
+	if len(pod.Labels) > 0 && template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	for key, value := range pod.Labels {
+		template.Labels[key] = value
+	}
+	return nil
+}
+
+// WaitForSidecarResult follows the sidecar logs until it reports the result of the command with ID.
+// Returns an error if the command failed.
+func WaitForSidecarResult(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod, id string) (*api.OperationResult, error) {
+	stream, err := cli.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: SidecarContainerName,
+		Follow:    true,
+	}).Stream(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to stream sidecar logs")
+	}
+	defer stream.Close() //nolint:errcheck
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		resultID, result, ok := ParseSidecarResultLine(scanner.Text())
+		if !ok || resultID != id {
+			continue
+		}
+		if len(result.Errors) > 0 {
+			return result, fmt.Errorf("Sidecar command %s failed: %s", id, strings.Join(result.Errors, "; "))
+		}
+		return result, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Unable to read sidecar logs")
+	}
+	return nil, fmt.Errorf("Sidecar stopped without reporting result of command %s", id)
+}
+
+// ParseSidecarResultLine parses a result line printed by the sidecar
+func ParseSidecarResultLine(line string) (string, *api.OperationResult, bool) {
+	data, found := strings.CutPrefix(line, sidecarResultPrefix)
+	if !found {
+		return "", nil, false
+	}
+	id, message, found := strings.Cut(data, " ")
+	if !found {
+		return "", nil, false
+	}
+	result, err := parseResultMessage(message)
+	if err != nil {
+		return "", nil, false
+	}
+	return id, result, true
+}
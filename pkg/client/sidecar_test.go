@@ -0,0 +1,81 @@
+package client
+
+import (
+	"testing"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/kanisterio/datamover/pkg/session"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSidecarCommand(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+
+	backup := SidecarCommand{Type: SidecarBackup, ID: "b1", Path: "/"}
+	matcher.Expect(backup.String()).To(gomega.Equal("backup b1 /"))
+	restore := SidecarCommand{Type: SidecarRestore, ID: "r1", Path: "/db", BackupID: "k123"}
+	matcher.Expect(restore.String()).To(gomega.Equal("restore r1 /db k123"))
+
+	exec, err := restore.ExecCommand("")
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(exec).To(gomega.Equal([]string{"sh", "-c", `echo "$0" > "$1"`, "restore r1 /db k123", SidecarCommandFile}))
+
+	_, err = SidecarCommand{Type: SidecarRestore, ID: "r1", Path: "/"}.ExecCommand("")
+	matcher.Expect(err).To(gomega.HaveOccurred())
+	_, err = SidecarCommand{Type: SidecarBackup, ID: "b1", Path: "/my dir"}.ExecCommand("")
+	matcher.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestInjectSidecar(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	deployment := appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app"}},
+					Volumes:    []corev1.Volume{{Name: "app-data"}},
+				},
+			},
+		},
+	}
+	args := CreateClientArgs{
+		Operation:         FileSystemSidecarOperation{VolumeMount: corev1.VolumeMount{Name: "app-data"}},
+		Image:             "client",
+		CredentialsConfig: ClientCredentialsToken{},
+	}
+	sessionConfig := session.SessionConfig{SessionUID: "session-uid"}
+
+	err := InjectSidecar(&deployment.Spec.Template, args, sessionConfig)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	template := deployment.Spec.Template
+	matcher.Expect(template.Spec.Containers).To(gomega.HaveLen(2))
+	sidecar := template.Spec.Containers[1]
+	matcher.Expect(sidecar.Name).To(gomega.Equal(SidecarContainerName))
+	matcher.Expect(sidecar.Args).To(gomega.Equal([]string{OpFsSidecar, SidecarCommandFile, sidecarDataMountPath}))
+	matcher.Expect(sidecar.VolumeMounts).To(gomega.ContainElement(corev1.VolumeMount{Name: "app-data", MountPath: sidecarDataMountPath}))
+	matcher.Expect(template.Spec.Volumes).To(gomega.ContainElement(gomega.HaveField("Name", tokenMoutName)))
+	matcher.Expect(template.Labels).To(gomega.HaveKeyWithValue(api.DatamoverClientLabel, "session-uid"))
+
+	err = InjectSidecar(&deployment.Spec.Template, args, sessionConfig)
+	matcher.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("already injected")))
+
+	args.Operation = FileSystemSidecarOperation{VolumeMount: corev1.VolumeMount{Name: "missing"}}
+	err = InjectSidecar(&corev1.PodTemplateSpec{}, args, sessionConfig)
+	matcher.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("not found")))
+}
+
+func TestParseSidecarResultLine(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+
+	id, result, ok := ParseSidecarResultLine(`result: b1 {"snapshotID":"k123","sizeBytes":10}`)
+	matcher.Expect(ok).To(gomega.BeTrue())
+	matcher.Expect(id).To(gomega.Equal("b1"))
+	matcher.Expect(result.SnapshotID).To(gomega.Equal("k123"))
+
+	_, _, ok = ParseSidecarResultLine("+ echo 'result: b1 {}'")
+	matcher.Expect(ok).To(gomega.BeFalse())
+	_, _, ok = ParseSidecarResultLine("result: b1 {")
+	matcher.Expect(ok).To(gomega.BeFalse())
+}
@@ -0,0 +1,299 @@
+package client
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// OperationFromSpec converts DatamoverOperation spec to client Operation
+func OperationFromSpec(spec api.DatamoverOperationSpec) (Operation, error) {
+	switch spec.Type {
+	case api.OperationTypeFsBackup:
+		if spec.FsBackup == nil {
+			return nil, fmt.Errorf("fsBackup is required for %s operation", spec.Type)
+		}
+		metadata, err := backupMetadata(spec.FsBackup.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		return FileSystemBackupOperation{
+			Path:          spec.FsBackup.Path,
+			Tag:           spec.FsBackup.Tag,
+			PVC:           spec.FsBackup.PVC,
+			ReadOnlyMount: spec.FsBackup.ReadOnlyMount,
+			Metadata:      metadata,
+		}, nil
+	case api.OperationTypeFsRestore:
+		if spec.FsRestore == nil {
+			return nil, fmt.Errorf("fsRestore is required for %s operation", spec.Type)
+		}
+		if err := validateRestorePaths(spec.FsRestore); err != nil {
+			return nil, err
+		}
+		return FileSystemRestoreOperation{
+			Path:            spec.FsRestore.Path,
+			BackupID:        spec.FsRestore.BackupID,
+			PVC:             spec.FsRestore.PVC,
+			Include:         spec.FsRestore.Include,
+			Exclude:         spec.FsRestore.Exclude,
+			OverwritePolicy: spec.FsRestore.OverwritePolicy,
+			SparseRestore:   spec.FsRestore.SparseRestore,
+		}, nil
+	case api.OperationTypeStreamBackup:
+		if spec.StreamBackup == nil {
+			return nil, fmt.Errorf("streamBackup is required for %s operation", spec.Type)
+		}
+		metadata, err := backupMetadata(spec.StreamBackup.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		return StreamBackupOperation{
+			Tag:              spec.StreamBackup.Tag,
+			Metadata:         metadata,
+			StreamGenerator:  spec.StreamBackup.StreamGenerator,
+			BackupObjectName: spec.StreamBackup.BackupObjectName,
+			InitImage:        spec.StreamBackup.InitImage,
+			Compression:      spec.StreamBackup.Compression,
+			Encryption:       spec.StreamBackup.Encryption,
+		}, nil
+	case api.OperationTypeStreamRestore:
+		if spec.StreamRestore == nil {
+			return nil, fmt.Errorf("streamRestore is required for %s operation", spec.Type)
+		}
+		return StreamRestoreOperation{
+			BackupID:         spec.StreamRestore.BackupID,
+			StreamIngestor:   spec.StreamRestore.StreamIngestor,
+			BackupObjectName: spec.StreamRestore.BackupObjectName,
+			InitImage:        spec.StreamRestore.InitImage,
+			Encryption:       spec.StreamRestore.Encryption,
+			Checksum:         spec.StreamRestore.Checksum,
+		}, nil
+	case api.OperationTypeMultiVolumeBackup:
+		if spec.MultiVolumeBackup == nil {
+			return nil, fmt.Errorf("multiVolumeBackup is required for %s operation", spec.Type)
+		}
+		if err := validateVolumeMap(spec.MultiVolumeBackup.Volumes); err != nil {
+			return nil, err
+		}
+		return MultiVolumeBackupOperation{
+			Volumes:       spec.MultiVolumeBackup.Volumes,
+			Tag:           spec.MultiVolumeBackup.Tag,
+			ReadOnlyMount: spec.MultiVolumeBackup.ReadOnlyMount,
+		}, nil
+	case api.OperationTypeMultiVolumeRestore:
+		if spec.MultiVolumeRestore == nil {
+			return nil, fmt.Errorf("multiVolumeRestore is required for %s operation", spec.Type)
+		}
+		if err := validateVolumeMap(spec.MultiVolumeRestore.Volumes); err != nil {
+			return nil, err
+		}
+		return MultiVolumeRestoreOperation{
+			Volumes:  spec.MultiVolumeRestore.Volumes,
+			BackupID: spec.MultiVolumeRestore.BackupID,
+		}, nil
+	case api.OperationTypeBlockBackup:
+		if spec.BlockBackup == nil {
+			return nil, fmt.Errorf("blockBackup is required for %s operation", spec.Type)
+		}
+		return BlockBackupOperation{
+			PVC: spec.BlockBackup.PVC,
+			Tag: spec.BlockBackup.Tag,
+		}, nil
+	case api.OperationTypeBlockRestore:
+		if spec.BlockRestore == nil {
+			return nil, fmt.Errorf("blockRestore is required for %s operation", spec.Type)
+		}
+		return BlockRestoreOperation{
+			PVC:           spec.BlockRestore.PVC,
+			BackupID:      spec.BlockRestore.BackupID,
+			SparseRestore: spec.BlockRestore.SparseRestore,
+		}, nil
+	case api.OperationTypeMultiStreamBackup:
+		if spec.MultiStreamBackup == nil {
+			return nil, fmt.Errorf("multiStreamBackup is required for %s operation", spec.Type)
+		}
+		if err := validateStreamNames(spec.MultiStreamBackup.Streams); err != nil {
+			return nil, err
+		}
+		return MultiStreamBackupOperation{
+			Streams:          spec.MultiStreamBackup.Streams,
+			StreamGenerators: spec.MultiStreamBackup.StreamGenerators,
+			Tag:              spec.MultiStreamBackup.Tag,
+			InitImage:        spec.MultiStreamBackup.InitImage,
+		}, nil
+	case api.OperationTypeMultiStreamRestore:
+		if spec.MultiStreamRestore == nil {
+			return nil, fmt.Errorf("multiStreamRestore is required for %s operation", spec.Type)
+		}
+		if err := validateStreamNames(spec.MultiStreamRestore.Streams); err != nil {
+			return nil, err
+		}
+		return MultiStreamRestoreOperation{
+			Streams:         spec.MultiStreamRestore.Streams,
+			StreamIngestors: spec.MultiStreamRestore.StreamIngestors,
+			BackupID:        spec.MultiStreamRestore.BackupID,
+			InitImage:       spec.MultiStreamRestore.InitImage,
+		}, nil
+	case api.OperationTypeBrowse:
+		if spec.Browse == nil {
+			return nil, fmt.Errorf("browse is required for %s operation", spec.Type)
+		}
+		return BrowseServerOperation{
+			BackupID: spec.Browse.BackupID,
+			Port:     spec.Browse.Port,
+			Auth:     spec.Browse.Auth,
+		}, nil
+	default:
+		return nil, fmt.Errorf("Unsupported operation type: %s", spec.Type)
+	}
+}
+
+// backupMetadata validates metadata, so it can be passed as implementation arguments
+func backupMetadata(metadata *api.BackupMetadata) (api.BackupMetadata, error) {
+	if metadata == nil {
+		return api.BackupMetadata{}, nil
+	}
+	for _, value := range []string{metadata.ParentSnapshotID, metadata.Source, metadata.RetentionHint} {
+		if strings.ContainsAny(value, " \t\n") {
+			return api.BackupMetadata{}, fmt.Errorf("Invalid backup metadata value: %q", value)
+		}
+	}
+	for key, value := range metadata.Labels {
+		if key == "" || strings.ContainsAny(key+value, " \t\n:=") {
+			return api.BackupMetadata{}, fmt.Errorf("Invalid backup metadata label: %q=%q", key, value)
+		}
+	}
+	return *metadata, nil
+}
+
+// validateRestorePaths checks that include and exclude paths are relative paths in the snapshot
+func validateRestorePaths(params *api.FsRestoreParams) error {
+	for _, restorePath := range slices.Concat(params.Include, params.Exclude) {
+		cleanPath := path.Clean(restorePath)
+		if restorePath == "" || strings.ContainsAny(restorePath, " \t\n") ||
+			path.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+			return fmt.Errorf("Invalid restore path: %q", restorePath)
+		}
+	}
+	return nil
+}
+
+// validateVolumeMap checks that volume names can be used as pod volume names and directories
+func validateVolumeMap(volumes map[string]string) error {
+	if len(volumes) == 0 {
+		return fmt.Errorf("At least one volume is required")
+	}
+	for name, pvc := range volumes {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return fmt.Errorf("Invalid volume name %s: %s", name, strings.Join(errs, ", "))
+		}
+		if pvc == "" {
+			return fmt.Errorf("PVC is required for volume %s", name)
+		}
+	}
+	return nil
+}
+
+// validateStreamNames checks that stream names can be used as file names and are unique
+func validateStreamNames(streams []string) error {
+	if len(streams) == 0 {
+		return fmt.Errorf("At least one stream is required")
+	}
+	for i, name := range streams {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("Invalid stream name %s: %s", name, strings.Join(errs, ", "))
+		}
+		if slices.Contains(streams[:i], name) {
+			return fmt.Errorf("Duplicate stream name %s", name)
+		}
+	}
+	return nil
+}
+
+// SnapshotBackupPVCName is the name of the temporary PVC and the VolumeSnapshot
+// created for snapshot_backup operation
+func SnapshotBackupPVCName(dmOperation api.DatamoverOperation) string {
+	return dmOperation.Name + "-snapshot"
+}
+
+// RestorePVCName is the name of the PVC to restore fs_restore operation to
+func RestorePVCName(dmOperation api.DatamoverOperation) string {
+	if dmOperation.Spec.FsRestore == nil || dmOperation.Spec.FsRestore.PVC == "" {
+		return dmOperation.Name + "-restore"
+	}
+	return dmOperation.Spec.FsRestore.PVC
+}
+
+// BrowseServiceName is the name of the service exposing the browse API of browse operation
+func BrowseServiceName(dmOperation api.DatamoverOperation) string {
+	return dmOperation.Name + "-browse"
+}
+
+// operationFromResource converts snapshot_backup operation to file system backup
+// of the temporary PVC and sets default PVC name for fs_restore,
+// other operations are converted from spec
+func operationFromResource(dmOperation api.DatamoverOperation) (Operation, error) {
+	spec := dmOperation.Spec
+	if spec.Type == api.OperationTypeFsRestore && spec.FsRestore != nil {
+		operation, err := OperationFromSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		restore := operation.(FileSystemRestoreOperation)
+		restore.PVC = RestorePVCName(dmOperation)
+		return restore, nil
+	}
+	if spec.Type != api.OperationTypeSnapshotBackup {
+		return OperationFromSpec(spec)
+	}
+	if spec.SnapshotBackup == nil {
+		return nil, fmt.Errorf("snapshotBackup is required for %s operation", spec.Type)
+	}
+	metadata, err := backupMetadata(spec.SnapshotBackup.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Source == "" {
+		metadata.Source = dmOperation.Namespace + "/" + spec.SnapshotBackup.PVC
+	}
+	return FileSystemBackupOperation{
+		Path:          spec.SnapshotBackup.Path,
+		Tag:           spec.SnapshotBackup.Tag,
+		PVC:           SnapshotBackupPVCName(dmOperation),
+		ReadOnlyMount: true,
+		Metadata:      metadata,
+	}, nil
+}
+
+// ClientArgsFromOperation creates client pod arguments to run DatamoverOperation
+func ClientArgsFromOperation(dmOperation api.DatamoverOperation) (CreateClientArgs, error) {
+	spec := dmOperation.Spec
+	operation, err := operationFromResource(dmOperation)
+	if err != nil {
+		return CreateClientArgs{}, err
+	}
+	var credentials ClientCredentialsConfig = ClientCredentialsToken{}
+	if spec.ClientSecretName != "" {
+		credentials = ClientCredentialsSecret{SecretName: spec.ClientSecretName}
+	}
+	return CreateClientArgs{
+		Operation:         operation,
+		Namespace:         dmOperation.Namespace,
+		Image:             spec.Image,
+		SessionNamespace:  dmOperation.Namespace,
+		SessionName:       spec.SessionRef.Name,
+		ConfigMap:         spec.ConfigMap,
+		Secrets:           spec.Secrets,
+		CredentialsConfig: credentials,
+		Env:               spec.Env,
+		PodOptions:        spec.PodOptions,
+		ProgressInterval:  time.Duration(spec.ProgressIntervalSeconds) * time.Second,
+		ProgressImage:     spec.ProgressImage,
+	}, nil
+}
@@ -0,0 +1,264 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultLogTailLines = 20
+	// Timeout to delete the pod after the wait context is cancelled
+	cancelDeleteTimeout = time.Second * 30
+)
+
+// ErrClientPodDeleted is returned when the client pod is deleted before it finishes
+var ErrClientPodDeleted = errors.New("Client pod was deleted")
+
+// FailureType tells which part of the client pod failed
+type FailureType string
+
+const (
+	// Init container creating the stream file failed
+	FailureStreamInit FailureType = "StreamInit"
+	// Other container failed, e.g. stream generator or stream ingestor
+	FailureContainer FailureType = "Container"
+	// Main datamover client container failed
+	FailureMainContainer FailureType = "MainContainer"
+	// Pod failed without container failures, e.g. evicted
+	FailurePod FailureType = "Pod"
+)
+
+// ClientPodError describes the failure of a client pod
+type ClientPodError struct {
+	Type FailureType
+	// Failed container, empty for FailurePod
+	Container string
+	ExitCode  int32
+	Message   string
+	// Last lines of the failed container logs
+	LogTail string
+	// Result reported by the main container, may contain client errors
+	Result *api.OperationResult
+}
+
+func (e *ClientPodError) Error() string {
+	if e.Container == "" {
+		return fmt.Sprintf("Client pod failed: %s", e.Message)
+	}
+	return fmt.Sprintf("Client pod container %s failed with exit code %d: %s", e.Container, e.ExitCode, e.Message)
+}
+
+// CompletionOptions control cleanup of the client pod in WaitForCompletion
+type CompletionOptions struct {
+	// Keep failed pods for debugging instead of deleting them
+	KeepFailedPod bool
+	// Number of log lines in ClientPodError.LogTail, defaults to 20
+	LogTailLines int64
+}
+
+// WaitForCompletion waits for the client pod to finish and deletes it.
+// Returns the result reported by the client or ClientPodError if the pod failed.
+// If ctx is cancelled, the pod is deleted to stop the operation.
+func WaitForCompletion(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod) (*api.OperationResult, error) {
+	return WaitForCompletionWithOptions(ctx, cli, pod, CompletionOptions{})
+}
+
+func WaitForCompletionWithOptions(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod, options CompletionOptions) (*api.OperationResult, error) {
+	finished, err := waitForFinished(ctx, cli, pod)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Use a new context, ctx is already cancelled
+			deleteCtx, cancel := context.WithTimeout(context.Background(), cancelDeleteTimeout)
+			defer cancel()
+			if err := DeleteClientPod(deleteCtx, cli, pod); err != nil {
+				log.Log.Error(err, "Unable to delete cancelled client pod", "pod", pod.Name)
+			}
+			return nil, errors.Wrap(ctx.Err(), "Client pod wait cancelled")
+		}
+		return nil, err
+	}
+
+	result, err := finishedResult(ctx, cli, *finished, options.LogTailLines)
+	if err == nil || !options.KeepFailedPod {
+		if err := DeleteClientPod(ctx, cli, finished); err != nil {
+			return result, err
+		}
+	}
+	return result, err
+}
+
+// WaitForClientPod waits for the client pod to finish and returns the operation result.
+// If the pod failed, the result contains errors reported by the client and ClientPodError is returned.
+// The pod is not deleted.
+func WaitForClientPod(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod) (*api.OperationResult, error) {
+	finished, err := waitForFinished(ctx, cli, pod)
+	if err != nil {
+		return nil, err
+	}
+	return finishedResult(ctx, cli, *finished, 0)
+}
+
+// DeleteClientPod deletes the client pod, not found pod is not an error
+func DeleteClientPod(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod) error {
+	err := cli.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "Unable to delete client pod")
+	}
+	return nil
+}
+
+// StreamLogs follows logs of the client pod container and writes them to out until the container stops.
+// Empty container name streams logs of the main container.
+func StreamLogs(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod, container string, out io.Writer) error {
+	if container == "" {
+		container = MainContainerName
+	}
+	stream, err := cli.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		Follow:    true,
+	}).Stream(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Unable to stream client pod logs")
+	}
+	defer stream.Close() //nolint:errcheck
+	_, err = io.Copy(out, stream)
+	return err
+}
+
+// GetLogTail returns last lines of the client pod container logs
+func GetLogTail(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod, container string, lines int64) (string, error) {
+	logs, err := cli.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &lines,
+	}).DoRaw(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to get client pod logs")
+	}
+	return string(logs), nil
+}
+
+// waitForFinished watches the pod until it succeeds or fails
+func waitForFinished(ctx context.Context, cli kubernetes.Interface, pod *corev1.Pod) (*corev1.Pod, error) {
+	selector := fields.OneTermEqualSelector("metadata.name", pod.Name).String()
+	pods := cli.CoreV1().Pods(pod.Namespace)
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector
+			return pods.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector
+			return pods.Watch(ctx, options)
+		},
+	}
+	event, err := watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, nil, func(event watch.Event) (bool, error) {
+		current, ok := event.Object.(*corev1.Pod)
+		if !ok || current.Name != pod.Name {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			return false, ErrClientPodDeleted
+		}
+		return podFinished(*current), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event.Object.(*corev1.Pod), nil
+}
+
+func podFinished(pod corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+func finishedResult(ctx context.Context, cli kubernetes.Interface, pod corev1.Pod, logTailLines int64) (*api.OperationResult, error) {
+	if pod.Status.Phase == corev1.PodSucceeded {
+		return ParseResult(pod)
+	}
+	podErr := ClassifyFailure(pod)
+	if podErr.Container != "" {
+		if logTailLines == 0 {
+			logTailLines = defaultLogTailLines
+		}
+		logTail, err := GetLogTail(ctx, cli, &pod, podErr.Container, logTailLines)
+		if err != nil {
+			log.Log.Error(err, "Unable to get failed container logs", "pod", pod.Name, "container", podErr.Container)
+		}
+		podErr.LogTail = logTail
+	}
+	return podErr.Result, podErr
+}
+
+// ClassifyFailure finds which container of the failed client pod failed.
+// Stream init and stream containers are checked before the main container,
+// because main container failure is often caused by a failed stream.
+func ClassifyFailure(pod corev1.Pod) *ClientPodError {
+	result := FailedResult(pod)
+	podErr := &ClientPodError{
+		Type:    FailurePod,
+		Message: strings.Join(result.Errors, "; "),
+		Result:  result,
+	}
+	// Progress sidecar is stopped after the client finishes and its exit code does not matter
+	initStatuses := slices.DeleteFunc(slices.Clone(pod.Status.InitContainerStatuses), func(status corev1.ContainerStatus) bool {
+		return status.Name == ProgressContainerName
+	})
+	if status, terminated := failedContainer(initStatuses); terminated != nil {
+		podErr.Type = FailureContainer
+		if status.Name == StreamInitContainerName {
+			podErr.Type = FailureStreamInit
+		}
+		setContainerFailure(podErr, status.Name, *terminated)
+		return podErr
+	}
+	others := slices.DeleteFunc(slices.Clone(pod.Status.ContainerStatuses), func(status corev1.ContainerStatus) bool {
+		return status.Name == MainContainerName
+	})
+	if status, terminated := failedContainer(others); terminated != nil {
+		podErr.Type = FailureContainer
+		setContainerFailure(podErr, status.Name, *terminated)
+		return podErr
+	}
+	if terminated := MainContainerTerminated(pod); terminated != nil && terminated.ExitCode != 0 {
+		podErr.Type = FailureMainContainer
+		podErr.Container = MainContainerName
+		podErr.ExitCode = terminated.ExitCode
+	}
+	return podErr
+}
+
+func failedContainer(statuses []corev1.ContainerStatus) (corev1.ContainerStatus, *corev1.ContainerStateTerminated) {
+	for _, status := range statuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return status, terminated
+		}
+	}
+	return corev1.ContainerStatus{}, nil
+}
+
+func setContainerFailure(podErr *ClientPodError, container string, terminated corev1.ContainerStateTerminated) {
+	podErr.Container = container
+	podErr.ExitCode = terminated.ExitCode
+	podErr.Message = strings.TrimSpace(terminated.Message)
+	if podErr.Message == "" {
+		podErr.Message = terminated.Reason
+	}
+}
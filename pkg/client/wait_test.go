@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func makeRunningPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "client", Namespace: "ns"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func terminatedStatus(name string, exitCode int32, message string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name: name,
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ExitCode: exitCode,
+			Message:  message,
+		}},
+	}
+}
+
+func TestWaitForCompletionWatchesPod(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	pod := makeRunningPod()
+	cli := kubefake.NewSimpleClientset(pod)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		finished := makeFinishedPod(corev1.PodSucceeded, `{"snapshotID":"k123"}`)
+		_, _ = cli.CoreV1().Pods("ns").UpdateStatus(ctx, finished, metav1.UpdateOptions{})
+	}()
+
+	result, err := WaitForCompletion(ctx, cli, pod)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(result.SnapshotID).To(gomega.Equal("k123"))
+
+	// Succeeded pod is deleted
+	_, err = cli.CoreV1().Pods("ns").Get(ctx, "client", metav1.GetOptions{})
+	matcher.Expect(apierrors.IsNotFound(err)).To(gomega.BeTrue())
+}
+
+func TestWaitForCompletionKeepFailedPod(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	pod := makeFinishedPod(corev1.PodFailed, `{"errors":["Failed to create snapshot"]}`)
+	pod.Status.ContainerStatuses[0].State.Terminated.ExitCode = 1
+	cli := kubefake.NewSimpleClientset(pod)
+
+	_, err := WaitForCompletionWithOptions(ctx, cli, pod, CompletionOptions{KeepFailedPod: true})
+	podErr, ok := err.(*ClientPodError)
+	matcher.Expect(ok).To(gomega.BeTrue())
+	matcher.Expect(podErr.Type).To(gomega.Equal(FailureMainContainer))
+	matcher.Expect(podErr.Message).To(gomega.Equal("Failed to create snapshot"))
+	matcher.Expect(podErr.LogTail).To(gomega.Equal("fake logs"))
+
+	_, err = cli.CoreV1().Pods("ns").Get(ctx, "client", metav1.GetOptions{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+}
+
+func TestWaitForCompletionCancelDeletesPod(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	pod := makeRunningPod()
+	cli := kubefake.NewSimpleClientset(pod)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := WaitForCompletion(ctx, cli, pod)
+	matcher.Expect(err).To(gomega.HaveOccurred())
+	_, err = cli.CoreV1().Pods("ns").Get(context.Background(), "client", metav1.GetOptions{})
+	matcher.Expect(apierrors.IsNotFound(err)).To(gomega.BeTrue())
+}
+
+func TestClassifyFailure(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	pod := corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed}}
+
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{terminatedStatus(StreamInitContainerName, 1, "mkfifo: File exists")}
+	podErr := ClassifyFailure(pod)
+	matcher.Expect(podErr.Type).To(gomega.Equal(FailureStreamInit))
+	matcher.Expect(podErr.Message).To(gomega.Equal("mkfifo: File exists"))
+
+	// Generator failure is reported over the main container failure
+	pod.Status.InitContainerStatuses = nil
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		terminatedStatus(MainContainerName, 1, `{"errors":["Failed to create snapshot"]}`),
+		terminatedStatus("generator", 2, "pg_dump: connection refused"),
+	}
+	podErr = ClassifyFailure(pod)
+	matcher.Expect(podErr.Type).To(gomega.Equal(FailureContainer))
+	matcher.Expect(podErr.Container).To(gomega.Equal("generator"))
+	matcher.Expect(podErr.ExitCode).To(gomega.BeEquivalentTo(2))
+	matcher.Expect(podErr.Result.Errors).To(gomega.ConsistOf("Failed to create snapshot"))
+
+	pod.Status.ContainerStatuses = nil
+	pod.Status.Reason = "Evicted"
+	podErr = ClassifyFailure(pod)
+	matcher.Expect(podErr.Type).To(gomega.Equal(FailurePod))
+	matcher.Expect(podErr.Error()).To(gomega.ContainSubstring("Evicted"))
+}
@@ -1,8 +1,17 @@
 package controller
 
 import (
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
@@ -12,9 +21,25 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	reconciler "github.com/kanisterio/datamover/internal/controller"
+	"github.com/kanisterio/datamover/pkg/generated/clientset/versioned"
+	"github.com/kanisterio/datamover/pkg/session"
 )
 
+// ConcurrencyLimits controls how many sessions can have running pods at the same time
+type ConcurrencyLimits = reconciler.ConcurrencyLimits
+
+// Config contains datamover specific controller settings
+type Config struct {
+	Limits ConcurrencyLimits
+	// Failed sessions older than this are deleted, 0 disables deletion
+	FailedSessionTTL time.Duration
+}
+
 func MakeControllerManager(restConfig *rest.Config, options ctrl.Options) (manager.Manager, error) {
+	return MakeControllerManagerWithConfig(restConfig, options, Config{})
+}
+
+func MakeControllerManagerWithConfig(restConfig *rest.Config, options ctrl.Options, config Config) (manager.Manager, error) {
 	if options.Scheme == nil {
 		scheme, err := makeScheme()
 		if err != nil {
@@ -22,6 +47,7 @@ func MakeControllerManager(restConfig *rest.Config, options ctrl.Options) (manag
 		}
 		options.Scheme = scheme
 	}
+	options.Cache.ByObject = withCacheSelectors(options.Cache.ByObject)
 	mgr, err := ctrl.NewManager(restConfig, options)
 	if err != nil {
 		log.Log.Error(err, "unable to start manager")
@@ -32,15 +58,87 @@ func MakeControllerManager(restConfig *rest.Config, options ctrl.Options) (manag
 		Client:     mgr.GetClient(),
 		Scheme:     mgr.GetScheme(),
 		RestConfig: *restConfig,
+		Limits:     config.Limits,
 	}).SetupWithManager(mgr); err != nil {
 		log.Log.Error(err, "unable to create controller", "controller", "DatamoverSession")
 		return nil, err
 	}
+	kubeCli, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		log.Log.Error(err, "unable to create kubernetes client")
+		return nil, err
+	}
+	if err = (&reconciler.DatamoverOperationReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		KubeClient: kubeCli,
+		APIReader:  mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		log.Log.Error(err, "unable to create controller", "controller", "DatamoverOperation")
+		return nil, err
+	}
 	// +kubebuilder:scaffold:builder
 
+	if config.FailedSessionTTL > 0 {
+		sessionClient, err := makeSessionClient(restConfig, kubeCli)
+		if err != nil {
+			log.Log.Error(err, "unable to create session client")
+			return nil, err
+		}
+		if err := mgr.Add(&session.SessionGC{
+			Client:    sessionClient,
+			FailedTTL: config.FailedSessionTTL,
+		}); err != nil {
+			log.Log.Error(err, "unable to add session garbage collector")
+			return nil, err
+		}
+	}
+
 	return mgr, nil
 }
 
+// withCacheSelectors limits cached leases and endpoint slices to ones
+// labelled by datamover, so unrelated objects in the cluster are not cached
+func withCacheSelectors(byObject map[client.Object]cache.ByObject) map[client.Object]cache.ByObject {
+	result := map[client.Object]cache.ByObject{}
+	hasLease := false
+	hasEndpointSlice := false
+	for obj, config := range byObject {
+		// Keep user provided configuration
+		switch obj.(type) {
+		case *coordinationv1.Lease:
+			hasLease = true
+		case *discoveryv1.EndpointSlice:
+			hasEndpointSlice = true
+		}
+		result[obj] = config
+	}
+	if !hasLease {
+		result[&coordinationv1.Lease{}] = cache.ByObject{Label: labelExistsSelector(api.DatamoverLeaseLabel)}
+	}
+	if !hasEndpointSlice {
+		result[&discoveryv1.EndpointSlice{}] = cache.ByObject{Label: labelExistsSelector(api.DatamoverSessionLabel)}
+	}
+	return result
+}
+
+func labelExistsSelector(label string) labels.Selector {
+	selector := labels.NewSelector()
+	requirement, err := labels.NewRequirement(label, selection.Exists, nil)
+	if err == nil {
+		selector = selector.Add(*requirement)
+	}
+	return selector
+}
+
+func makeSessionClient(restConfig *rest.Config, kubeCli kubernetes.Interface) (session.Client, error) {
+	dmCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return session.NewTypedClient(dmCli, kubeCli), nil
+}
+
 func makeScheme() (*runtime.Scheme, error) {
 	scheme := runtime.NewScheme()
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
@@ -13,7 +13,7 @@ import (
 // things we have original files at `pkg/customresource` and have soft links
 // at `helm/kanister-operator/crds`.
 
-//go:embed datamoversession.yaml
+//go:embed datamoversession.yaml datamoveroperation.yaml
 var yaml embed.FS
 
 func ReadCRD(name string) ([]byte, error) {
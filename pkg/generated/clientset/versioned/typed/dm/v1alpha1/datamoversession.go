@@ -0,0 +1,194 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/kanisterio/datamover/api/v1alpha1"
+	scheme "github.com/kanisterio/datamover/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// DatamoverSessionsGetter has a method to return a DatamoverSessionInterface.
+// A group's client should implement this interface.
+type DatamoverSessionsGetter interface {
+	DatamoverSessions(namespace string) DatamoverSessionInterface
+}
+
+// DatamoverSessionInterface has methods to work with DatamoverSession resources.
+type DatamoverSessionInterface interface {
+	Create(ctx context.Context, datamoverSession *v1alpha1.DatamoverSession, opts v1.CreateOptions) (*v1alpha1.DatamoverSession, error)
+	Update(ctx context.Context, datamoverSession *v1alpha1.DatamoverSession, opts v1.UpdateOptions) (*v1alpha1.DatamoverSession, error)
+	UpdateStatus(ctx context.Context, datamoverSession *v1alpha1.DatamoverSession, opts v1.UpdateOptions) (*v1alpha1.DatamoverSession, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.DatamoverSession, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.DatamoverSessionList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.DatamoverSession, err error)
+	DatamoverSessionExpansion
+}
+
+// datamoverSessions implements DatamoverSessionInterface
+type datamoverSessions struct {
+	client rest.Interface
+	ns     string
+}
+
+// newDatamoverSessions returns a DatamoverSessions
+func newDatamoverSessions(c *DmV1alpha1Client, namespace string) *datamoverSessions {
+	return &datamoverSessions{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the datamoverSession, and returns the corresponding datamoverSession object, and an error if there is any.
+func (c *datamoverSessions) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.DatamoverSession, err error) {
+	result = &v1alpha1.DatamoverSession{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("datamoversessions").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of DatamoverSessions that match those selectors.
+func (c *datamoverSessions) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.DatamoverSessionList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.DatamoverSessionList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("datamoversessions").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested datamoverSessions.
+func (c *datamoverSessions) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("datamoversessions").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a datamoverSession and creates it.  Returns the server's representation of the datamoverSession, and an error, if there is any.
+func (c *datamoverSessions) Create(ctx context.Context, datamoverSession *v1alpha1.DatamoverSession, opts v1.CreateOptions) (result *v1alpha1.DatamoverSession, err error) {
+	result = &v1alpha1.DatamoverSession{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("datamoversessions").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(datamoverSession).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a datamoverSession and updates it. Returns the server's representation of the datamoverSession, and an error, if there is any.
+func (c *datamoverSessions) Update(ctx context.Context, datamoverSession *v1alpha1.DatamoverSession, opts v1.UpdateOptions) (result *v1alpha1.DatamoverSession, err error) {
+	result = &v1alpha1.DatamoverSession{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("datamoversessions").
+		Name(datamoverSession.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(datamoverSession).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *datamoverSessions) UpdateStatus(ctx context.Context, datamoverSession *v1alpha1.DatamoverSession, opts v1.UpdateOptions) (result *v1alpha1.DatamoverSession, err error) {
+	result = &v1alpha1.DatamoverSession{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("datamoversessions").
+		Name(datamoverSession.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(datamoverSession).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the datamoverSession and deletes it. Returns an error if one occurs.
+func (c *datamoverSessions) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("datamoversessions").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *datamoverSessions) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("datamoversessions").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched datamoverSession.
+func (c *datamoverSessions) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.DatamoverSession, err error) {
+	result = &v1alpha1.DatamoverSession{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("datamoversessions").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
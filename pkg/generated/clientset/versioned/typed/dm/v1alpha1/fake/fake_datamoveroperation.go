@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/kanisterio/datamover/api/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeDatamoverOperations implements DatamoverOperationInterface
+type FakeDatamoverOperations struct {
+	Fake *FakeDmV1alpha1
+	ns   string
+}
+
+var datamoveroperationsResource = v1alpha1.SchemeGroupVersion.WithResource("datamoveroperations")
+
+var datamoveroperationsKind = v1alpha1.SchemeGroupVersion.WithKind("DatamoverOperation")
+
+// Get takes name of the datamoverOperation, and returns the corresponding datamoverOperation object, and an error if there is any.
+func (c *FakeDatamoverOperations) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.DatamoverOperation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(datamoveroperationsResource, c.ns, name), &v1alpha1.DatamoverOperation{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DatamoverOperation), err
+}
+
+// List takes label and field selectors, and returns the list of DatamoverOperations that match those selectors.
+func (c *FakeDatamoverOperations) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.DatamoverOperationList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(datamoveroperationsResource, datamoveroperationsKind, c.ns, opts), &v1alpha1.DatamoverOperationList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.DatamoverOperationList{ListMeta: obj.(*v1alpha1.DatamoverOperationList).ListMeta}
+	for _, item := range obj.(*v1alpha1.DatamoverOperationList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested datamoverOperations.
+func (c *FakeDatamoverOperations) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(datamoveroperationsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a datamoverOperation and creates it.  Returns the server's representation of the datamoverOperation, and an error, if there is any.
+func (c *FakeDatamoverOperations) Create(ctx context.Context, datamoverOperation *v1alpha1.DatamoverOperation, opts v1.CreateOptions) (result *v1alpha1.DatamoverOperation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(datamoveroperationsResource, c.ns, datamoverOperation), &v1alpha1.DatamoverOperation{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DatamoverOperation), err
+}
+
+// Update takes the representation of a datamoverOperation and updates it. Returns the server's representation of the datamoverOperation, and an error, if there is any.
+func (c *FakeDatamoverOperations) Update(ctx context.Context, datamoverOperation *v1alpha1.DatamoverOperation, opts v1.UpdateOptions) (result *v1alpha1.DatamoverOperation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(datamoveroperationsResource, c.ns, datamoverOperation), &v1alpha1.DatamoverOperation{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DatamoverOperation), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeDatamoverOperations) UpdateStatus(ctx context.Context, datamoverOperation *v1alpha1.DatamoverOperation, opts v1.UpdateOptions) (*v1alpha1.DatamoverOperation, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(datamoveroperationsResource, "status", c.ns, datamoverOperation), &v1alpha1.DatamoverOperation{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DatamoverOperation), err
+}
+
+// Delete takes name of the datamoverOperation and deletes it. Returns an error if one occurs.
+func (c *FakeDatamoverOperations) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(datamoveroperationsResource, c.ns, name, opts), &v1alpha1.DatamoverOperation{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeDatamoverOperations) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(datamoveroperationsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.DatamoverOperationList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched datamoverOperation.
+func (c *FakeDatamoverOperations) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.DatamoverOperation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(datamoveroperationsResource, c.ns, name, pt, data, subresources...), &v1alpha1.DatamoverOperation{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DatamoverOperation), err
+}
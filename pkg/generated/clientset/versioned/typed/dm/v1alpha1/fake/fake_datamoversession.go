@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/kanisterio/datamover/api/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeDatamoverSessions implements DatamoverSessionInterface
+type FakeDatamoverSessions struct {
+	Fake *FakeDmV1alpha1
+	ns   string
+}
+
+var datamoversessionsResource = v1alpha1.SchemeGroupVersion.WithResource("datamoversessions")
+
+var datamoversessionsKind = v1alpha1.SchemeGroupVersion.WithKind("DatamoverSession")
+
+// Get takes name of the datamoverSession, and returns the corresponding datamoverSession object, and an error if there is any.
+func (c *FakeDatamoverSessions) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.DatamoverSession, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(datamoversessionsResource, c.ns, name), &v1alpha1.DatamoverSession{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DatamoverSession), err
+}
+
+// List takes label and field selectors, and returns the list of DatamoverSessions that match those selectors.
+func (c *FakeDatamoverSessions) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.DatamoverSessionList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(datamoversessionsResource, datamoversessionsKind, c.ns, opts), &v1alpha1.DatamoverSessionList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.DatamoverSessionList{ListMeta: obj.(*v1alpha1.DatamoverSessionList).ListMeta}
+	for _, item := range obj.(*v1alpha1.DatamoverSessionList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested datamoverSessions.
+func (c *FakeDatamoverSessions) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(datamoversessionsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a datamoverSession and creates it.  Returns the server's representation of the datamoverSession, and an error, if there is any.
+func (c *FakeDatamoverSessions) Create(ctx context.Context, datamoverSession *v1alpha1.DatamoverSession, opts v1.CreateOptions) (result *v1alpha1.DatamoverSession, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(datamoversessionsResource, c.ns, datamoverSession), &v1alpha1.DatamoverSession{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DatamoverSession), err
+}
+
+// Update takes the representation of a datamoverSession and updates it. Returns the server's representation of the datamoverSession, and an error, if there is any.
+func (c *FakeDatamoverSessions) Update(ctx context.Context, datamoverSession *v1alpha1.DatamoverSession, opts v1.UpdateOptions) (result *v1alpha1.DatamoverSession, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(datamoversessionsResource, c.ns, datamoverSession), &v1alpha1.DatamoverSession{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DatamoverSession), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeDatamoverSessions) UpdateStatus(ctx context.Context, datamoverSession *v1alpha1.DatamoverSession, opts v1.UpdateOptions) (*v1alpha1.DatamoverSession, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(datamoversessionsResource, "status", c.ns, datamoverSession), &v1alpha1.DatamoverSession{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DatamoverSession), err
+}
+
+// Delete takes name of the datamoverSession and deletes it. Returns an error if one occurs.
+func (c *FakeDatamoverSessions) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(datamoversessionsResource, c.ns, name, opts), &v1alpha1.DatamoverSession{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeDatamoverSessions) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(datamoversessionsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.DatamoverSessionList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched datamoverSession.
+func (c *FakeDatamoverSessions) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.DatamoverSession, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(datamoversessionsResource, c.ns, name, pt, data, subresources...), &v1alpha1.DatamoverSession{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.DatamoverSession), err
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	time "time"
+
+	dmv1alpha1 "github.com/kanisterio/datamover/api/v1alpha1"
+	versioned "github.com/kanisterio/datamover/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/kanisterio/datamover/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/kanisterio/datamover/pkg/generated/listers/dm/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// DatamoverOperationInformer provides access to a shared informer and lister for
+// DatamoverOperations.
+type DatamoverOperationInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.DatamoverOperationLister
+}
+
+type datamoverOperationInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewDatamoverOperationInformer constructs a new informer for DatamoverOperation type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewDatamoverOperationInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredDatamoverOperationInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredDatamoverOperationInformer constructs a new informer for DatamoverOperation type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredDatamoverOperationInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DmV1alpha1().DatamoverOperations(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DmV1alpha1().DatamoverOperations(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&dmv1alpha1.DatamoverOperation{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *datamoverOperationInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredDatamoverOperationInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *datamoverOperationInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&dmv1alpha1.DatamoverOperation{}, f.defaultInformer)
+}
+
+func (f *datamoverOperationInformer) Lister() v1alpha1.DatamoverOperationLister {
+	return v1alpha1.NewDatamoverOperationLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/kanisterio/datamover/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DatamoverOperationLister helps list DatamoverOperations.
+// All objects returned here must be treated as read-only.
+type DatamoverOperationLister interface {
+	// List lists all DatamoverOperations in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.DatamoverOperation, err error)
+	// DatamoverOperations returns an object that can list and get DatamoverOperations.
+	DatamoverOperations(namespace string) DatamoverOperationNamespaceLister
+	DatamoverOperationListerExpansion
+}
+
+// datamoverOperationLister implements the DatamoverOperationLister interface.
+type datamoverOperationLister struct {
+	indexer cache.Indexer
+}
+
+// NewDatamoverOperationLister returns a new DatamoverOperationLister.
+func NewDatamoverOperationLister(indexer cache.Indexer) DatamoverOperationLister {
+	return &datamoverOperationLister{indexer: indexer}
+}
+
+// List lists all DatamoverOperations in the indexer.
+func (s *datamoverOperationLister) List(selector labels.Selector) (ret []*v1alpha1.DatamoverOperation, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.DatamoverOperation))
+	})
+	return ret, err
+}
+
+// DatamoverOperations returns an object that can list and get DatamoverOperations.
+func (s *datamoverOperationLister) DatamoverOperations(namespace string) DatamoverOperationNamespaceLister {
+	return datamoverOperationNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// DatamoverOperationNamespaceLister helps list and get DatamoverOperations.
+// All objects returned here must be treated as read-only.
+type DatamoverOperationNamespaceLister interface {
+	// List lists all DatamoverOperations in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.DatamoverOperation, err error)
+	// Get retrieves the DatamoverOperation from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.DatamoverOperation, error)
+	DatamoverOperationNamespaceListerExpansion
+}
+
+// datamoverOperationNamespaceLister implements the DatamoverOperationNamespaceLister
+// interface.
+type datamoverOperationNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all DatamoverOperations in the indexer for a given namespace.
+func (s datamoverOperationNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.DatamoverOperation, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.DatamoverOperation))
+	})
+	return ret, err
+}
+
+// Get retrieves the DatamoverOperation from the indexer for a given namespace and name.
+func (s datamoverOperationNamespaceLister) Get(name string) (*v1alpha1.DatamoverOperation, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("datamoveroperation"), name)
+	}
+	return obj.(*v1alpha1.DatamoverOperation), nil
+}
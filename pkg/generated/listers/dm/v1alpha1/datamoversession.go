@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/kanisterio/datamover/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DatamoverSessionLister helps list DatamoverSessions.
+// All objects returned here must be treated as read-only.
+type DatamoverSessionLister interface {
+	// List lists all DatamoverSessions in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.DatamoverSession, err error)
+	// DatamoverSessions returns an object that can list and get DatamoverSessions.
+	DatamoverSessions(namespace string) DatamoverSessionNamespaceLister
+	DatamoverSessionListerExpansion
+}
+
+// datamoverSessionLister implements the DatamoverSessionLister interface.
+type datamoverSessionLister struct {
+	indexer cache.Indexer
+}
+
+// NewDatamoverSessionLister returns a new DatamoverSessionLister.
+func NewDatamoverSessionLister(indexer cache.Indexer) DatamoverSessionLister {
+	return &datamoverSessionLister{indexer: indexer}
+}
+
+// List lists all DatamoverSessions in the indexer.
+func (s *datamoverSessionLister) List(selector labels.Selector) (ret []*v1alpha1.DatamoverSession, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.DatamoverSession))
+	})
+	return ret, err
+}
+
+// DatamoverSessions returns an object that can list and get DatamoverSessions.
+func (s *datamoverSessionLister) DatamoverSessions(namespace string) DatamoverSessionNamespaceLister {
+	return datamoverSessionNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// DatamoverSessionNamespaceLister helps list and get DatamoverSessions.
+// All objects returned here must be treated as read-only.
+type DatamoverSessionNamespaceLister interface {
+	// List lists all DatamoverSessions in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.DatamoverSession, err error)
+	// Get retrieves the DatamoverSession from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.DatamoverSession, error)
+	DatamoverSessionNamespaceListerExpansion
+}
+
+// datamoverSessionNamespaceLister implements the DatamoverSessionNamespaceLister
+// interface.
+type datamoverSessionNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all DatamoverSessions in the indexer for a given namespace.
+func (s datamoverSessionNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.DatamoverSession, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.DatamoverSession))
+	})
+	return ret, err
+}
+
+// Get retrieves the DatamoverSession from the indexer for a given namespace and name.
+func (s datamoverSessionNamespaceLister) Get(name string) (*v1alpha1.DatamoverSession, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("datamoversession"), name)
+	}
+	return obj.(*v1alpha1.DatamoverSession), nil
+}
@@ -0,0 +1,241 @@
+package session
+
+import (
+	"context"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/kanisterio/datamover/pkg/generated/clientset/versioned"
+	dmlisters "github.com/kanisterio/datamover/pkg/generated/listers/dm/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// Client is used by session functions to access sessions and their services.
+// It can be created from a dynamic client, a typed clientset or informer listers.
+type Client interface {
+	GetSession(ctx context.Context, name, namespace string) (*api.DatamoverSession, error)
+	// ListSessions lists sessions matching the selector, empty namespace lists all namespaces
+	ListSessions(ctx context.Context, namespace string, selector labels.Selector) ([]api.DatamoverSession, error)
+	CreateSession(ctx context.Context, dmSession api.DatamoverSession) (*api.DatamoverSession, error)
+	DeleteSession(ctx context.Context, dmSession api.DatamoverSession) error
+	GetService(ctx context.Context, name, namespace string) (*corev1.Service, error)
+	ListEndpointSlices(ctx context.Context, serviceName, namespace string) ([]discoveryv1.EndpointSlice, error)
+}
+
+type dynamicClient struct {
+	dynCli dynamic.Interface
+}
+
+var _ Client = dynamicClient{}
+
+// NewDynamicClient creates session client using dynamic interface
+func NewDynamicClient(dynCli dynamic.Interface) Client {
+	return dynamicClient{dynCli: dynCli}
+}
+
+func (c dynamicClient) GetSession(ctx context.Context, name, namespace string) (*api.DatamoverSession, error) {
+	client := c.dynCli.Resource(api.GroupVersion.WithResource(ResourceNamePlural)).Namespace(namespace)
+	us, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	dataMoverSession := api.DatamoverSession{}
+	err = runtime.DefaultUnstructuredConverter.
+		FromUnstructured(us.UnstructuredContent(), &dataMoverSession)
+	if err != nil {
+		return nil, err
+	}
+	return &dataMoverSession, nil
+}
+
+func (c dynamicClient) ListSessions(ctx context.Context, namespace string, selector labels.Selector) ([]api.DatamoverSession, error) {
+	client := c.dynCli.Resource(api.GroupVersion.WithResource(ResourceNamePlural)).Namespace(namespace)
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]api.DatamoverSession, 0, len(list.Items))
+	for _, item := range list.Items {
+		dataMoverSession := api.DatamoverSession{}
+		err = runtime.DefaultUnstructuredConverter.
+			FromUnstructured(item.UnstructuredContent(), &dataMoverSession)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dataMoverSession)
+	}
+	return result, nil
+}
+
+func (c dynamicClient) CreateSession(ctx context.Context, dmSession api.DatamoverSession) (*api.DatamoverSession, error) {
+	dmSession.Kind = api.DatamoverSessionKind
+	dmSession.APIVersion = api.GroupVersion.String()
+
+	client := c.dynCli.Resource(api.GroupVersion.WithResource(ResourceNamePlural)).Namespace(dmSession.Namespace)
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&dmSession)
+	if err != nil {
+		return nil, err
+	}
+	us := &unstructured.Unstructured{}
+	us.SetUnstructuredContent(data)
+
+	res, err := client.Create(ctx, us, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	dataMoverSession := api.DatamoverSession{}
+	err = runtime.DefaultUnstructuredConverter.
+		FromUnstructured(res.UnstructuredContent(), &dataMoverSession)
+	if err != nil {
+		return nil, err
+	}
+	return &dataMoverSession, nil
+}
+
+func (c dynamicClient) DeleteSession(ctx context.Context, dmSession api.DatamoverSession) error {
+	client := c.dynCli.Resource(api.GroupVersion.WithResource(ResourceNamePlural)).Namespace(dmSession.Namespace)
+	return client.Delete(ctx, dmSession.Name, metav1.DeleteOptions{})
+}
+
+func (c dynamicClient) GetService(ctx context.Context, name, namespace string) (*corev1.Service, error) {
+	serviceData, err := c.dynCli.Resource(corev1.SchemeGroupVersion.WithResource("services")).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var svc corev1.Service
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(serviceData.Object, &svc)
+	if err != nil {
+		return nil, err
+	}
+	return &svc, nil
+}
+
+func (c dynamicClient) ListEndpointSlices(ctx context.Context, serviceName, namespace string) ([]discoveryv1.EndpointSlice, error) {
+	client := c.dynCli.Resource(discoveryv1.SchemeGroupVersion.WithResource("endpointslices")).Namespace(namespace)
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]discoveryv1.EndpointSlice, 0, len(list.Items))
+	for _, item := range list.Items {
+		slice := discoveryv1.EndpointSlice{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredContent(), &slice)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, slice)
+	}
+	return result, nil
+}
+
+type typedClient struct {
+	dmCli   versioned.Interface
+	kubeCli kubernetes.Interface
+}
+
+var _ Client = typedClient{}
+
+// NewTypedClient creates session client using generated clientset
+func NewTypedClient(dmCli versioned.Interface, kubeCli kubernetes.Interface) Client {
+	return typedClient{dmCli: dmCli, kubeCli: kubeCli}
+}
+
+func (c typedClient) GetSession(ctx context.Context, name, namespace string) (*api.DatamoverSession, error) {
+	return c.dmCli.DmV1alpha1().DatamoverSessions(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (c typedClient) ListSessions(ctx context.Context, namespace string, selector labels.Selector) ([]api.DatamoverSession, error) {
+	list, err := c.dmCli.DmV1alpha1().DatamoverSessions(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c typedClient) CreateSession(ctx context.Context, dmSession api.DatamoverSession) (*api.DatamoverSession, error) {
+	return c.dmCli.DmV1alpha1().DatamoverSessions(dmSession.Namespace).Create(ctx, &dmSession, metav1.CreateOptions{})
+}
+
+func (c typedClient) DeleteSession(ctx context.Context, dmSession api.DatamoverSession) error {
+	return c.dmCli.DmV1alpha1().DatamoverSessions(dmSession.Namespace).Delete(ctx, dmSession.Name, metav1.DeleteOptions{})
+}
+
+func (c typedClient) GetService(ctx context.Context, name, namespace string) (*corev1.Service, error) {
+	return c.kubeCli.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (c typedClient) ListEndpointSlices(ctx context.Context, serviceName, namespace string) ([]discoveryv1.EndpointSlice, error) {
+	list, err := c.kubeCli.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+type cachedClient struct {
+	typedClient
+	sessionLister dmlisters.DatamoverSessionLister
+	serviceLister corelisters.ServiceLister
+}
+
+var _ Client = cachedClient{}
+
+// NewCachedClient creates session client reading sessions and services from informer listers.
+// Informers should be started and synced before using the client.
+// Writes and endpoint slice reads go to the API server using the clientset.
+func NewCachedClient(
+	dmCli versioned.Interface,
+	kubeCli kubernetes.Interface,
+	sessionLister dmlisters.DatamoverSessionLister,
+	serviceLister corelisters.ServiceLister,
+) Client {
+	return cachedClient{
+		typedClient:   typedClient{dmCli: dmCli, kubeCli: kubeCli},
+		sessionLister: sessionLister,
+		serviceLister: serviceLister,
+	}
+}
+
+func (c cachedClient) GetSession(ctx context.Context, name, namespace string) (*api.DatamoverSession, error) {
+	dmSession, err := c.sessionLister.DatamoverSessions(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	// Objects in the cache are shared
+	return dmSession.DeepCopy(), nil
+}
+
+func (c cachedClient) ListSessions(ctx context.Context, namespace string, selector labels.Selector) ([]api.DatamoverSession, error) {
+	var list []*api.DatamoverSession
+	var err error
+	if namespace == "" {
+		list, err = c.sessionLister.List(selector)
+	} else {
+		list, err = c.sessionLister.DatamoverSessions(namespace).List(selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := make([]api.DatamoverSession, 0, len(list))
+	for _, item := range list {
+		result = append(result, *item.DeepCopy())
+	}
+	return result, nil
+}
+
+func (c cachedClient) GetService(ctx context.Context, name, namespace string) (*corev1.Service, error) {
+	svc, err := c.serviceLister.Services(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return svc.DeepCopy(), nil
+}
@@ -0,0 +1,105 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	dmfake "github.com/kanisterio/datamover/pkg/generated/clientset/versioned/fake"
+	dminformers "github.com/kanisterio/datamover/pkg/generated/informers/externalversions"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func makeReadySession() *api.DatamoverSession {
+	return &api.DatamoverSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "session",
+			Namespace: "ns",
+			UID:       "session-uid",
+		},
+		Spec: api.DatamoverSessionSpec{
+			Implementation: "kopia",
+		},
+		Status: api.DatamoverSessionStatus{
+			Progress: api.ProgressReady,
+			SessionInfo: api.SessionInfo{
+				ServiceName: "session-service",
+				SessionData: "data",
+			},
+		},
+	}
+}
+
+func makeSessionService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "session-service",
+			Namespace: "ns",
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "10.0.0.1",
+			Ports:     []corev1.ServicePort{{Name: "foo", Port: 2000}},
+		},
+	}
+}
+
+func TestGetConfigWithTypedClient(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	cli := NewTypedClient(dmfake.NewSimpleClientset(makeReadySession()), kubefake.NewSimpleClientset(makeSessionService()))
+
+	config, err := GetConfigWithClient(ctx, cli, "session", "ns")
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(config.Implementation).To(gomega.Equal("kopia"))
+	matcher.Expect(config.SessionData).To(gomega.Equal("data"))
+	matcher.Expect(config.SessionUID).To(gomega.BeEquivalentTo("session-uid"))
+	matcher.Expect(config.ClusterDomain).To(gomega.Equal(defaultClusterDomain))
+	matcher.Expect(config.Service.Name).To(gomega.Equal("session-service"))
+
+	endpoints, err := GetServiceEndpointsWithClient(ctx, cli, "session", "ns")
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(endpoints).To(gomega.Equal(map[string]string{"foo": "10.0.0.1:2000"}))
+}
+
+func TestCreateDeleteWithTypedClient(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	dmCli := dmfake.NewSimpleClientset()
+	cli := NewTypedClient(dmCli, kubefake.NewSimpleClientset())
+
+	created, err := cli.CreateSession(ctx, *makeReadySession())
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(created.Name).To(gomega.Equal("session"))
+
+	err = cli.DeleteSession(ctx, *created)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	_, err = cli.GetSession(ctx, "session", "ns")
+	matcher.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestGetConfigWithCachedClient(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dmCli := dmfake.NewSimpleClientset(makeReadySession())
+	kubeCli := kubefake.NewSimpleClientset(makeSessionService())
+	dmFactory := dminformers.NewSharedInformerFactory(dmCli, 0)
+	kubeFactory := informers.NewSharedInformerFactory(kubeCli, 0)
+	sessionLister := dmFactory.Dm().V1alpha1().DatamoverSessions().Lister()
+	serviceLister := kubeFactory.Core().V1().Services().Lister()
+	dmFactory.Start(ctx.Done())
+	kubeFactory.Start(ctx.Done())
+	dmFactory.WaitForCacheSync(ctx.Done())
+	kubeFactory.WaitForCacheSync(ctx.Done())
+
+	cli := NewCachedClient(dmCli, kubeCli, sessionLister, serviceLister)
+	config, err := GetConfigWithClient(ctx, cli, "session", "ns")
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(config.Service.Spec.ClusterIP).To(gomega.Equal("10.0.0.1"))
+}
@@ -0,0 +1,99 @@
+package session
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// ErrNoReadyEndpoints is returned when the session service has no ready endpoints
+var ErrNoReadyEndpoints = errors.New("Service has no ready endpoints")
+
+// ServiceEndpoints contains host:port addresses of the session service per port name
+type ServiceEndpoints struct {
+	// Service DNS name
+	DNS map[string]string
+	// Service cluster IPs, empty for headless services
+	ClusterIP map[string][]string
+	// Ready pod endpoints backing the service
+	PodIP map[string][]string
+}
+
+func ResolveServiceEndpoints(ctx context.Context, dynCli dynamic.Interface, sessionName, sessionNamespace string) (*ServiceEndpoints, error) {
+	return ResolveServiceEndpointsWithClient(ctx, NewDynamicClient(dynCli), sessionName, sessionNamespace)
+}
+
+// ResolveServiceEndpointsWithClient waits for the session to be ready and resolves
+// its service addresses using endpoint slices.
+// Returns ErrNoReadyEndpoints if no pod is ready to serve clients.
+func ResolveServiceEndpointsWithClient(ctx context.Context, cli Client, sessionName, sessionNamespace string) (*ServiceEndpoints, error) {
+	sessionConfig, err := GetConfigWithClient(ctx, cli, sessionName, sessionNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if sessionConfig.Service == nil {
+		return nil, errors.New("Session config does not have a service")
+	}
+	slices, err := cli.ListEndpointSlices(ctx, sessionConfig.Service.Name, sessionConfig.Service.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to list service endpoint slices")
+	}
+	result := makeServiceEndpoints(*sessionConfig.Service, sessionConfig.ClusterDomain, slices)
+	if len(result.PodIP) == 0 {
+		return result, ErrNoReadyEndpoints
+	}
+	return result, nil
+}
+
+func makeServiceEndpoints(service corev1.Service, clusterDomain string, slices []discoveryv1.EndpointSlice) *ServiceEndpoints {
+	result := &ServiceEndpoints{
+		DNS:       map[string]string{},
+		ClusterIP: map[string][]string{},
+		PodIP:     map[string][]string{},
+	}
+	hostname := ServiceHostname(service, clusterDomain)
+	clusterIPs := service.Spec.ClusterIPs
+	if len(clusterIPs) == 0 && service.Spec.ClusterIP != "" {
+		clusterIPs = []string{service.Spec.ClusterIP}
+	}
+	for _, port := range service.Spec.Ports {
+		result.DNS[port.Name] = joinHostPort(hostname, port.Port)
+		for _, ip := range clusterIPs {
+			if ip == corev1.ClusterIPNone {
+				continue
+			}
+			result.ClusterIP[port.Name] = append(result.ClusterIP[port.Name], joinHostPort(ip, port.Port))
+		}
+	}
+
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			// Nil ready condition should be interpreted as ready
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			for _, port := range slice.Ports {
+				if port.Port == nil {
+					continue
+				}
+				name := ""
+				if port.Name != nil {
+					name = *port.Name
+				}
+				for _, address := range endpoint.Addresses {
+					result.PodIP[name] = append(result.PodIP[name], joinHostPort(address, *port.Port))
+				}
+			}
+		}
+	}
+	return result
+}
+
+func joinHostPort(host string, port int32) string {
+	return net.JoinHostPort(host, strconv.FormatInt(int64(port), 10))
+}
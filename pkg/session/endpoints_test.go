@@ -0,0 +1,49 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	dmfake "github.com/kanisterio/datamover/pkg/generated/clientset/versioned/fake"
+	"github.com/onsi/gomega"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func makeEndpointSlice(ready bool) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "session-service-abc",
+			Namespace: "ns",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "session-service"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{{
+			Addresses:  []string{"10.1.0.5"},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)},
+		}},
+		Ports: []discoveryv1.EndpointPort{{Name: ptr.To("foo"), Port: ptr.To(int32(8080))}},
+	}
+}
+
+func TestResolveServiceEndpoints(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	cli := NewTypedClient(dmfake.NewSimpleClientset(makeReadySession()), kubefake.NewSimpleClientset(makeSessionService(), makeEndpointSlice(true)))
+
+	endpoints, err := ResolveServiceEndpointsWithClient(context.Background(), cli, "session", "ns")
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(endpoints.DNS).To(gomega.Equal(map[string]string{"foo": "session-service.ns.svc.cluster.local:2000"}))
+	matcher.Expect(endpoints.ClusterIP).To(gomega.Equal(map[string][]string{"foo": {"10.0.0.1:2000"}}))
+	matcher.Expect(endpoints.PodIP).To(gomega.Equal(map[string][]string{"foo": {"10.1.0.5:8080"}}))
+}
+
+func TestResolveServiceEndpointsNotReady(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	cli := NewTypedClient(dmfake.NewSimpleClientset(makeReadySession()), kubefake.NewSimpleClientset(makeSessionService(), makeEndpointSlice(false)))
+
+	endpoints, err := ResolveServiceEndpointsWithClient(context.Background(), cli, "session", "ns")
+	matcher.Expect(err).To(gomega.MatchError(ErrNoReadyEndpoints))
+	matcher.Expect(endpoints.DNS).To(gomega.HaveKey("foo"))
+}
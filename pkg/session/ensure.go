@@ -0,0 +1,161 @@
+package session
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Length of the hash in hex characters, fits into label values and resource names
+	specHashLength = 32
+	// Prefix for names of sessions created by EnsureSession
+	ensuredNamePrefix = "datamover-"
+)
+
+// SpecHash returns a canonical hash of the session spec.
+// All spec fields except suspend are hashed, so sessions with the same hash run
+// the same server with the same exposure, pod options, network policy and sharing.
+// Service ports are sorted as their order does not change the session.
+func SpecHash(spec api.DatamoverSessionSpec) (string, error) {
+	canonical := spec.DeepCopy()
+	// Suspend is changed by callers while the session is running
+	canonical.Suspend = false
+	if canonical.LifecycleConfig != nil {
+		slices.SortFunc(canonical.LifecycleConfig.ServicePorts, func(a, b corev1.ServicePort) int {
+			return cmp.Or(
+				cmp.Compare(a.Port, b.Port),
+				cmp.Compare(a.Protocol, b.Protocol),
+				cmp.Compare(a.Name, b.Name),
+			)
+		})
+	}
+	// Maps are marshalled with sorted keys
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to marshal session spec")
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:specHashLength], nil
+}
+
+// EnsureSession returns an existing compatible session in the namespace or creates a new one.
+// Compatible sessions have the same SpecHash of their current spec and are Ready or ResourcesCreated,
+// mutable fields of the spec can be changed after the hash label is set.
+// New sessions get a name derived from the hash, so concurrent callers
+// get the same session instead of creating duplicates.
+// Failed session with the same name is deleted and replaced with a new one,
+// unless it is pinned with DatamoverSessionPinLabel.
+func EnsureSession(ctx context.Context, cli Client, namespace string, spec api.DatamoverSessionSpec) (*api.DatamoverSession, error) {
+	hash, err := SpecHash(spec)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := cli.ListSessions(ctx, namespace, labels.SelectorFromSet(labels.Set{api.DatamoverSessionHashLabel: hash}))
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to list sessions")
+	}
+	found, err := findCompatible(existing, hash)
+	if err != nil {
+		return nil, err
+	}
+	if found != nil {
+		return found, nil
+	}
+
+	dmSession := api.DatamoverSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ensuredNamePrefix + hash,
+			Namespace: namespace,
+			Labels:    map[string]string{api.DatamoverSessionHashLabel: hash},
+		},
+		Spec: spec,
+	}
+	current, err := createOrGet(ctx, cli, dmSession)
+	if err != nil {
+		return nil, err
+	}
+	// Pinned sessions are kept to trace errors
+	if current.DeletionTimestamp == nil && slices.Contains(FailedProgress, current.Status.Progress) && !isPinned(*current) {
+		log.Log.Info("Replacing failed session", "session", current.Name, "namespace", namespace, "progress", current.Status.Progress)
+		if err := cli.DeleteSession(ctx, *current); err != nil && !apierrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "Unable to delete failed session")
+		}
+		current, err = createOrGet(ctx, cli, dmSession)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if current.DeletionTimestamp != nil || slices.Contains(FailedProgress, current.Status.Progress) {
+		return nil, fmt.Errorf("Session %s is failed or being deleted, retry after it is deleted", current.Name)
+	}
+	currentHash, err := SpecHash(current.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if currentHash != hash {
+		return nil, fmt.Errorf("Session %s spec was changed after it was created", current.Name)
+	}
+	return current, nil
+}
+
+// createOrGet creates the session or gets the existing one with the same name and hash.
+// Existing session can be created concurrently by another caller.
+func createOrGet(ctx context.Context, cli Client, dmSession api.DatamoverSession) (*api.DatamoverSession, error) {
+	created, err := cli.CreateSession(ctx, dmSession)
+	if err == nil {
+		return created, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return nil, errors.Wrap(err, "Unable to create session")
+	}
+	current, err := cli.GetSession(ctx, dmSession.Name, dmSession.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to get existing session")
+	}
+	hash := dmSession.Labels[api.DatamoverSessionHashLabel]
+	if current.Labels[api.DatamoverSessionHashLabel] != hash {
+		return nil, fmt.Errorf("Session %s exists with a different spec hash", current.Name)
+	}
+	return current, nil
+}
+
+// findCompatible prefers Ready sessions over sessions which are still starting.
+// Sessions with the spec changed after the hash label was set are skipped.
+func findCompatible(sessions []api.DatamoverSession, hash string) (*api.DatamoverSession, error) {
+	var starting *api.DatamoverSession
+	for i := range sessions {
+		item := &sessions[i]
+		if item.DeletionTimestamp != nil {
+			continue
+		}
+		itemHash, err := SpecHash(item.Spec)
+		if err != nil {
+			return nil, err
+		}
+		if itemHash != hash {
+			continue
+		}
+		switch item.Status.Progress {
+		case api.ProgressReady:
+			return item, nil
+		case api.ProgressResourcesCreated:
+			if starting == nil {
+				starting = item
+			}
+		}
+	}
+	return starting, nil
+}
@@ -0,0 +1,175 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func makeEnsureSpec() api.DatamoverSessionSpec {
+	return api.DatamoverSessionSpec{
+		Implementation: "kopia",
+		ConfigurationSecrets: map[string]corev1.SecretVolumeSource{
+			"repo": {SecretName: "repo-secret"},
+		},
+		LifecycleConfig: &api.LifecycleConfig{
+			Image: "kopia:latest",
+			ServicePorts: []corev1.ServicePort{
+				{Name: "b", Port: 2000},
+				{Name: "a", Port: 1000},
+			},
+		},
+	}
+}
+
+func specHash(t *testing.T, spec api.DatamoverSessionSpec) string {
+	hash, err := SpecHash(spec)
+	gomega.NewWithT(t).Expect(err).ToNot(gomega.HaveOccurred())
+	return hash
+}
+
+func TestSpecHashIsCanonical(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	spec := makeEnsureSpec()
+	hash := specHash(t, spec)
+	matcher.Expect(hash).To(gomega.HaveLen(specHashLength))
+
+	// Port order and non-reference fields do not change the hash
+	reordered := makeEnsureSpec()
+	reordered.LifecycleConfig.ServicePorts = []corev1.ServicePort{
+		{Name: "a", Port: 1000},
+		{Name: "b", Port: 2000},
+	}
+	reordered.Suspend = true
+	matcher.Expect(specHash(t, reordered)).To(gomega.Equal(hash))
+
+	changed := makeEnsureSpec()
+	changed.LifecycleConfig.Image = "kopia:other"
+	matcher.Expect(specHash(t, changed)).ToNot(gomega.Equal(hash))
+
+	// Ports with the same number are ordered by protocol and name
+	samePort := makeEnsureSpec()
+	samePort.LifecycleConfig.ServicePorts = []corev1.ServicePort{
+		{Name: "udp", Port: 1000, Protocol: corev1.ProtocolUDP},
+		{Name: "tcp", Port: 1000, Protocol: corev1.ProtocolTCP},
+		{Name: "b", Port: 2000},
+	}
+	swapped := samePort.DeepCopy()
+	slices.Reverse(swapped.LifecycleConfig.ServicePorts)
+	matcher.Expect(specHash(t, *swapped)).To(gomega.Equal(specHash(t, samePort)))
+}
+
+func TestSpecHashIncludesServerOptions(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	hash := specHash(t, makeEnsureSpec())
+	changes := map[string]func(spec *api.DatamoverSessionSpec){
+		"service": func(spec *api.DatamoverSessionSpec) {
+			spec.LifecycleConfig.Service = &api.ServiceConfig{Type: api.ServiceTypeLoadBalancer}
+		},
+		"podOptions": func(spec *api.DatamoverSessionSpec) {
+			spec.LifecycleConfig.PodOptions.ServiceAccount = "other"
+		},
+		"networkPolicy": func(spec *api.DatamoverSessionSpec) {
+			spec.LifecycleConfig.NetworkPolicy.Enabled = true
+		},
+		"sharing": func(spec *api.DatamoverSessionSpec) {
+			spec.Sharing = &api.SharingConfig{IdleTimeoutSeconds: 60}
+		},
+	}
+	for name, change := range changes {
+		spec := makeEnsureSpec()
+		change(&spec)
+		matcher.Expect(specHash(t, spec)).ToNot(gomega.Equal(hash), name)
+	}
+}
+
+func TestEnsureSessionCreatesOnce(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	cli := makeListClient()
+	spec := makeEnsureSpec()
+
+	created, err := EnsureSession(ctx, cli, "ns", spec)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(created.Name).To(gomega.Equal(ensuredNamePrefix + specHash(t, spec)))
+	matcher.Expect(created.Labels).To(gomega.HaveKeyWithValue(api.DatamoverSessionHashLabel, specHash(t, spec)))
+
+	// Session is not ready yet, second call gets the same session by name
+	again, err := EnsureSession(ctx, cli, "ns", spec)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(again.Name).To(gomega.Equal(created.Name))
+
+	list, err := ListWithClient(ctx, cli, "ns", ListFilter{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(list).To(gomega.HaveLen(1))
+}
+
+func TestEnsureSessionReturnsReadySession(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	spec := makeEnsureSpec()
+	ready := makeListSession("existing", "kopia", api.ProgressReady, 0)
+	ready.Spec = spec
+	ready.Labels[api.DatamoverSessionHashLabel] = specHash(t, spec)
+	cli := makeListClient(ready)
+
+	found, err := EnsureSession(context.Background(), cli, "ns", spec)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(found.Name).To(gomega.Equal("existing"))
+}
+
+func TestEnsureSessionSkipsChangedSession(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	spec := makeEnsureSpec()
+	// Network policy was changed after the session was created with the hash label
+	changed := makeListSession("changed", "kopia", api.ProgressReady, 0)
+	changed.Spec = *spec.DeepCopy()
+	changed.Spec.LifecycleConfig.NetworkPolicy.Enabled = true
+	changed.Labels[api.DatamoverSessionHashLabel] = specHash(t, spec)
+	cli := makeListClient(changed)
+
+	created, err := EnsureSession(ctx, cli, "ns", spec)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(created.Name).To(gomega.Equal(ensuredNamePrefix + specHash(t, spec)))
+
+	// Session created by EnsureSession and changed later is not returned
+	current, err := cli.GetSession(ctx, created.Name, "ns")
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	current.Spec.LifecycleConfig.NetworkPolicy.Enabled = true
+	current.Status.Progress = api.ProgressReady
+	cli = makeListClient(current)
+	_, err = EnsureSession(ctx, cli, "ns", spec)
+	matcher.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("spec was changed")))
+}
+
+func TestEnsureSessionReplacesFailedSession(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	spec := makeEnsureSpec()
+	failed := makeListSession(ensuredNamePrefix+specHash(t, spec), "kopia", api.ProgressSessionFailure, 0)
+	failed.Spec = spec
+	failed.Labels[api.DatamoverSessionHashLabel] = specHash(t, spec)
+	cli := makeListClient(failed)
+
+	replaced, err := EnsureSession(ctx, cli, "ns", spec)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(replaced.Name).To(gomega.Equal(failed.Name))
+	matcher.Expect(replaced.Status.Progress).To(gomega.Equal(api.ProgressNone))
+}
+
+func TestEnsureSessionKeepsPinnedFailedSession(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	spec := makeEnsureSpec()
+	failed := makeListSession(ensuredNamePrefix+specHash(t, spec), "kopia", api.ProgressSessionFailure, 0)
+	failed.Spec = spec
+	failed.Labels[api.DatamoverSessionHashLabel] = specHash(t, spec)
+	failed.Labels[api.DatamoverSessionPinLabel] = "true"
+	cli := makeListClient(failed)
+
+	_, err := EnsureSession(context.Background(), cli, "ns", spec)
+	matcher.Expect(err).To(gomega.HaveOccurred())
+}
@@ -0,0 +1,55 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const defaultGCInterval = time.Minute * 5
+
+// SessionGC periodically deletes sessions which failed more than FailedTTL ago.
+// It implements controller-runtime manager.Runnable and can also be started
+// directly by consumers.
+type SessionGC struct {
+	Client Client
+	// Namespace to collect sessions in, empty for all namespaces
+	Namespace string
+	// Sessions are deleted once they have been failed for longer than this
+	FailedTTL time.Duration
+	// How often to check for sessions to delete, defaults to 5 minutes
+	Interval time.Duration
+}
+
+// Start runs garbage collection until the context is cancelled
+func (gc *SessionGC) Start(ctx context.Context) error {
+	interval := gc.Interval
+	if interval <= 0 {
+		interval = defaultGCInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := gc.RunOnce(ctx); err != nil {
+			// Retry on the next pass
+			log.Log.Error(err, "Session garbage collection failed")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs a single garbage collection pass and returns deleted sessions
+func (gc *SessionGC) RunOnce(ctx context.Context) ([]api.DatamoverSession, error) {
+	return DeleteFailedOlderThanWithClient(ctx, gc.Client, gc.Namespace, gc.FailedTTL)
+}
+
+// NeedLeaderElection makes the manager run only one collector in the cluster
+func (gc *SessionGC) NeedLeaderElection() bool {
+	return true
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+)
+
+// DefaultLeaseDuration is used when Acquire is called with zero duration
+const DefaultLeaseDuration = time.Minute
+
+// Acquire creates a lease for the holder on a shared session.
+// The lease should be renewed before it expires and released when the client is done.
+// Session is kept running while it has active leases. Idle session is resumed
+// by the controller, callers should wait for it to be ready before connecting.
+func Acquire(ctx context.Context, kubeCli kubernetes.Interface, dmSession api.DatamoverSession, holder string, duration time.Duration) (*coordinationv1.Lease, error) {
+	if dmSession.Spec.Sharing == nil {
+		return nil, errors.New("Session is not shared")
+	}
+	if dmSession.DeletionTimestamp != nil {
+		return nil, errors.New("Session is being deleted")
+	}
+	if duration <= 0 {
+		duration = DefaultLeaseDuration
+	}
+	now := metav1.NewMicroTime(time.Now())
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: dmSession.Name + "-lease-",
+			Namespace:    dmSession.Namespace,
+			Labels: map[string]string{
+				api.DatamoverLeaseLabel: dmSession.Name,
+			},
+			// Leases are garbage collected with the session
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: api.GroupVersion.String(),
+				Kind:       api.DatamoverSessionKind,
+				Name:       dmSession.Name,
+				UID:        dmSession.UID,
+			}},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To(holder),
+			LeaseDurationSeconds: ptr.To(int32(duration / time.Second)),
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	}
+	lease, err := kubeCli.CoordinationV1().Leases(dmSession.Namespace).Create(ctx, lease, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to create session lease")
+	}
+	return lease, nil
+}
+
+// Renew extends the lease by its duration
+func Renew(ctx context.Context, kubeCli kubernetes.Interface, lease *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+	renewed := lease.DeepCopy()
+	now := metav1.NewMicroTime(time.Now())
+	renewed.Spec.RenewTime = &now
+	renewed, err := kubeCli.CoordinationV1().Leases(lease.Namespace).Update(ctx, renewed, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to renew session lease")
+	}
+	return renewed, nil
+}
+
+// Release deletes the lease. Releasing already deleted lease is not an error.
+func Release(ctx context.Context, kubeCli kubernetes.Interface, lease *coordinationv1.Lease) error {
+	err := kubeCli.CoordinationV1().Leases(lease.Namespace).Delete(ctx, lease.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "Unable to release session lease")
+	}
+	return nil
+}
+
+// LeaseExpiry returns the time when the lease expires if not renewed
+func LeaseExpiry(lease coordinationv1.Lease) time.Time {
+	renewTime := lease.CreationTimestamp.Time
+	if lease.Spec.RenewTime != nil {
+		renewTime = lease.Spec.RenewTime.Time
+	}
+	duration := DefaultLeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	return renewTime.Add(duration)
+}
+
+// LeaseActive checks that the lease is held and not expired
+func LeaseActive(lease coordinationv1.Lease, now time.Time) bool {
+	if lease.DeletionTimestamp != nil || lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" {
+		return false
+	}
+	return now.Before(LeaseExpiry(lease))
+}
@@ -0,0 +1,48 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAcquireRequiresSharedSession(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	_, err := Acquire(context.Background(), kubefake.NewSimpleClientset(), *makeReadySession(), "client", time.Minute)
+	matcher.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestAcquireRenewRelease(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	kubeCli := kubefake.NewSimpleClientset()
+	dmSession := makeReadySession()
+	dmSession.Spec.Sharing = &api.SharingConfig{IdleTimeoutSeconds: 60}
+
+	lease, err := Acquire(ctx, kubeCli, *dmSession, "client", 0)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(lease.Labels).To(gomega.HaveKeyWithValue(api.DatamoverLeaseLabel, "session"))
+	matcher.Expect(lease.OwnerReferences).To(gomega.HaveLen(1))
+	matcher.Expect(lease.OwnerReferences[0].UID).To(gomega.Equal(dmSession.UID))
+	matcher.Expect(*lease.Spec.LeaseDurationSeconds).To(gomega.BeEquivalentTo(60))
+	matcher.Expect(LeaseActive(*lease, time.Now())).To(gomega.BeTrue())
+	matcher.Expect(LeaseActive(*lease, time.Now().Add(2*time.Minute))).To(gomega.BeFalse())
+
+	// Fake client does not generate names
+	lease.Name = "session-lease-1"
+	lease, err = kubeCli.CoordinationV1().Leases("ns").Create(ctx, lease, metav1.CreateOptions{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+
+	renewed, err := Renew(ctx, kubeCli, lease)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(renewed.Spec.RenewTime.Time).ToNot(gomega.BeTemporally("<", lease.Spec.RenewTime.Time))
+
+	matcher.Expect(Release(ctx, kubeCli, renewed)).To(gomega.Succeed())
+	// Releasing twice is not an error
+	matcher.Expect(Release(ctx, kubeCli, renewed)).To(gomega.Succeed())
+}
@@ -0,0 +1,147 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ListFilter selects sessions returned by List. Empty fields match all sessions.
+type ListFilter struct {
+	// Label selector, nil matches all labels
+	Selector       labels.Selector
+	Implementation string
+	// Sessions with any of these progress values
+	Progress []api.DatamoverSessionProgress
+	// Sessions which have an owner reference with this UID
+	OwnerUID types.UID
+	// Sessions created before this time
+	CreatedBefore *time.Time
+	// Sessions which failed before this time.
+	// Sessions without recorded failure time are matched by creation time.
+	FailedBefore *time.Time
+}
+
+func (filter ListFilter) matches(dmSession api.DatamoverSession) bool {
+	if filter.Implementation != "" && dmSession.Spec.Implementation != filter.Implementation {
+		return false
+	}
+	if len(filter.Progress) > 0 && !slices.Contains(filter.Progress, dmSession.Status.Progress) {
+		return false
+	}
+	if filter.OwnerUID != "" && !slices.ContainsFunc(dmSession.OwnerReferences, func(ref metav1.OwnerReference) bool {
+		return ref.UID == filter.OwnerUID
+	}) {
+		return false
+	}
+	if filter.CreatedBefore != nil && !dmSession.CreationTimestamp.Time.Before(*filter.CreatedBefore) {
+		return false
+	}
+	if filter.FailedBefore != nil && !failedTime(dmSession).Before(*filter.FailedBefore) {
+		return false
+	}
+	return true
+}
+
+func failedTime(dmSession api.DatamoverSession) time.Time {
+	if dmSession.Status.FailedTime != nil {
+		return dmSession.Status.FailedTime.Time
+	}
+	return dmSession.CreationTimestamp.Time
+}
+
+// FailedProgress lists progress values of sessions which will not recover
+var FailedProgress = []api.DatamoverSessionProgress{
+	api.ProgressValidationFailed,
+	api.ProgressReadinessFailure,
+	api.ProgressSessionFailure,
+}
+
+// List returns sessions in the namespace matching the filter, empty namespace lists all namespaces
+func List(ctx context.Context, dynCli dynamic.Interface, namespace string, filter ListFilter) ([]api.DatamoverSession, error) {
+	return ListWithClient(ctx, NewDynamicClient(dynCli), namespace, filter)
+}
+
+func ListWithClient(ctx context.Context, cli Client, namespace string, filter ListFilter) ([]api.DatamoverSession, error) {
+	selector := filter.Selector
+	if selector == nil {
+		selector = labels.Everything()
+	}
+	list, err := cli.ListSessions(ctx, namespace, selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to list sessions")
+	}
+	result := []api.DatamoverSession{}
+	for _, item := range list {
+		if filter.matches(item) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// ListByOwner returns sessions in the namespace with owner reference to the owner object
+func ListByOwner(ctx context.Context, dynCli dynamic.Interface, owner metav1.Object) ([]api.DatamoverSession, error) {
+	return ListByOwnerWithClient(ctx, NewDynamicClient(dynCli), owner)
+}
+
+func ListByOwnerWithClient(ctx context.Context, cli Client, owner metav1.Object) ([]api.DatamoverSession, error) {
+	return ListWithClient(ctx, cli, owner.GetNamespace(), ListFilter{OwnerUID: owner.GetUID()})
+}
+
+// DeleteFailedOlderThan deletes sessions which failed more than age ago.
+// Sessions pinned with DatamoverSessionPinLabel are kept.
+// Returns deleted sessions.
+func DeleteFailedOlderThan(ctx context.Context, dynCli dynamic.Interface, namespace string, age time.Duration) ([]api.DatamoverSession, error) {
+	return DeleteFailedOlderThanWithClient(ctx, NewDynamicClient(dynCli), namespace, age)
+}
+
+func DeleteFailedOlderThanWithClient(ctx context.Context, cli Client, namespace string, age time.Duration) ([]api.DatamoverSession, error) {
+	failedBefore := time.Now().Add(-age)
+	return deleteMatching(ctx, cli, namespace, ListFilter{Progress: FailedProgress, FailedBefore: &failedBefore})
+}
+
+// DeleteOlderThanWithClient deletes sessions matching the filter created more than age ago.
+// Sessions pinned with DatamoverSessionPinLabel are kept.
+// Returns deleted sessions.
+func DeleteOlderThanWithClient(ctx context.Context, cli Client, namespace string, filter ListFilter, age time.Duration) ([]api.DatamoverSession, error) {
+	createdBefore := time.Now().Add(-age)
+	filter.CreatedBefore = &createdBefore
+	return deleteMatching(ctx, cli, namespace, filter)
+}
+
+func deleteMatching(ctx context.Context, cli Client, namespace string, filter ListFilter) ([]api.DatamoverSession, error) {
+	list, err := ListWithClient(ctx, cli, namespace, filter)
+	if err != nil {
+		return nil, err
+	}
+	deleted := []api.DatamoverSession{}
+	for _, item := range list {
+		if isPinned(item) || item.DeletionTimestamp != nil {
+			continue
+		}
+		err := cli.DeleteSession(ctx, item)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return deleted, errors.Wrapf(err, "Unable to delete session %s/%s", item.Namespace, item.Name)
+		}
+		log.Log.Info("Deleted session", "session", item.Name, "namespace", item.Namespace, "progress", item.Status.Progress)
+		deleted = append(deleted, item)
+	}
+	return deleted, nil
+}
+
+func isPinned(dmSession api.DatamoverSession) bool {
+	return dmSession.Labels[api.DatamoverSessionPinLabel] == "true"
+}
@@ -0,0 +1,113 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/kanisterio/datamover/api/v1alpha1"
+	dmfake "github.com/kanisterio/datamover/pkg/generated/clientset/versioned/fake"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func makeListSession(name, implementation string, progress api.DatamoverSessionProgress, age time.Duration) *api.DatamoverSession {
+	return &api.DatamoverSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ns",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			Labels:            map[string]string{"app": implementation},
+		},
+		Spec: api.DatamoverSessionSpec{
+			Implementation: implementation,
+		},
+		Status: api.DatamoverSessionStatus{
+			Progress: progress,
+		},
+	}
+}
+
+func makeListClient(sessions ...*api.DatamoverSession) Client {
+	objects := []runtime.Object{}
+	for _, item := range sessions {
+		objects = append(objects, item)
+	}
+	return NewTypedClient(dmfake.NewSimpleClientset(objects...), kubefake.NewSimpleClientset())
+}
+
+func sessionNames(sessions []api.DatamoverSession) []string {
+	names := []string{}
+	for _, item := range sessions {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+func TestListFilters(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	owned := makeListSession("owned", "kopia", api.ProgressReady, time.Minute)
+	owned.OwnerReferences = []metav1.OwnerReference{{Name: "owner", UID: "owner-uid"}}
+	cli := makeListClient(
+		owned,
+		makeListSession("failed", "kopia", api.ProgressSessionFailure, time.Minute),
+		makeListSession("noop", "noop", api.ProgressReady, time.Minute),
+	)
+
+	list, err := ListWithClient(ctx, cli, "ns", ListFilter{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(sessionNames(list)).To(gomega.ConsistOf("owned", "failed", "noop"))
+
+	list, err = ListWithClient(ctx, cli, "ns", ListFilter{Implementation: "kopia", Progress: []api.DatamoverSessionProgress{api.ProgressReady}})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(sessionNames(list)).To(gomega.ConsistOf("owned"))
+
+	list, err = ListWithClient(ctx, cli, "", ListFilter{Selector: labels.SelectorFromSet(labels.Set{"app": "noop"})})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(sessionNames(list)).To(gomega.ConsistOf("noop"))
+
+	owner := &metav1.ObjectMeta{Name: "owner", Namespace: "ns", UID: "owner-uid"}
+	list, err = ListByOwnerWithClient(ctx, cli, owner)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(sessionNames(list)).To(gomega.ConsistOf("owned"))
+}
+
+func TestDeleteFailedOlderThan(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	pinned := makeListSession("pinned", "kopia", api.ProgressSessionFailure, time.Hour)
+	pinned.Labels[api.DatamoverSessionPinLabel] = "true"
+	cli := makeListClient(
+		pinned,
+		makeListSession("old-failed", "kopia", api.ProgressReadinessFailure, time.Hour),
+		makeListSession("new-failed", "kopia", api.ProgressSessionFailure, time.Second),
+		makeListSession("old-ready", "kopia", api.ProgressReady, time.Hour),
+	)
+
+	gc := &SessionGC{Client: cli, FailedTTL: time.Minute}
+	deleted, err := gc.RunOnce(ctx)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(sessionNames(deleted)).To(gomega.ConsistOf("old-failed"))
+
+	list, err := ListWithClient(ctx, cli, "ns", ListFilter{})
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(sessionNames(list)).To(gomega.ConsistOf("pinned", "new-failed", "old-ready"))
+}
+
+func TestDeleteFailedUsesFailedTime(t *testing.T) {
+	matcher := gomega.NewWithT(t)
+	ctx := context.Background()
+	recentlyFailed := makeListSession("recently-failed", "kopia", api.ProgressSessionFailure, time.Hour)
+	recentlyFailed.Status.FailedTime = &metav1.Time{Time: time.Now().Add(-time.Second)}
+	longFailed := makeListSession("long-failed", "kopia", api.ProgressSessionFailure, time.Hour)
+	longFailed.Status.FailedTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	cli := makeListClient(recentlyFailed, longFailed)
+
+	deleted, err := DeleteFailedOlderThanWithClient(ctx, cli, "ns", time.Minute)
+	matcher.Expect(err).ToNot(gomega.HaveOccurred())
+	matcher.Expect(sessionNames(deleted)).To(gomega.ConsistOf("long-failed"))
+}
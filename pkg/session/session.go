@@ -9,30 +9,54 @@ import (
 	api "github.com/kanisterio/datamover/api/v1alpha1"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 const (
-	clusterLocalDomain = "svc.cluster.local"
-	ResourceNamePlural = "datamoversessions"
-	ResourceName       = "datamoversession"
-	waitTimeout        = time.Second * 120
-	waitInterval       = time.Second * 5
+	defaultClusterDomain = "cluster.local"
+	ResourceNamePlural   = "datamoversessions"
+	ResourceName         = "datamoversession"
+	waitTimeout          = time.Second * 120
+	waitInterval         = time.Second * 5
 )
 
 type SessionConfig struct {
 	Implementation string
 	Service        *corev1.Service
 	SessionData    string
+	// Used to label client pods so network policy can allow them
+	SessionUID types.UID
+	// Cluster domain used to build service DNS name
+	ClusterDomain string
+	// Endpoints reported by the session
+	Endpoints []api.SessionEndpoint
+}
+
+// ClusterDomain returns cluster domain configured for the session service
+func ClusterDomain(dmSession api.DatamoverSession) string {
+	lifecycle := dmSession.Spec.LifecycleConfig
+	if lifecycle != nil && lifecycle.Service != nil && lifecycle.Service.ClusterDomain != "" {
+		return lifecycle.Service.ClusterDomain
+	}
+	return defaultClusterDomain
+}
+
+// ServiceHostname returns DNS name of the service in the cluster
+func ServiceHostname(service corev1.Service, clusterDomain string) string {
+	if clusterDomain == "" {
+		clusterDomain = defaultClusterDomain
+	}
+	return service.Name + "." + service.Namespace + ".svc." + clusterDomain
 }
 
 func GetServiceEndpoints(ctx context.Context, dynCli dynamic.Interface, sessionName, sessionNamespace string) (map[string]string, error) {
-	sessionConfig, err := GetConfig(ctx, dynCli, sessionName, sessionNamespace)
+	return GetServiceEndpointsWithClient(ctx, NewDynamicClient(dynCli), sessionName, sessionNamespace)
+}
+
+func GetServiceEndpointsWithClient(ctx context.Context, cli Client, sessionName, sessionNamespace string) (map[string]string, error) {
+	sessionConfig, err := GetConfigWithClient(ctx, cli, sessionName, sessionNamespace)
 	if err != nil {
 		return nil, err
 	}
@@ -40,6 +64,10 @@ func GetServiceEndpoints(ctx context.Context, dynCli dynamic.Interface, sessionN
 		return nil, errors.New("Session config does not have a service")
 	}
 	hostname := sessionConfig.Service.Spec.ClusterIP
+	// Headless service is only reachable via DNS name
+	if hostname == "" || hostname == corev1.ClusterIPNone {
+		hostname = ServiceHostname(*sessionConfig.Service, sessionConfig.ClusterDomain)
+	}
 	result := map[string]string{}
 	for _, portSpec := range sessionConfig.Service.Spec.Ports {
 		hostPort := net.JoinHostPort(hostname, strconv.FormatInt(int64(portSpec.Port), 10))
@@ -49,50 +77,57 @@ func GetServiceEndpoints(ctx context.Context, dynCli dynamic.Interface, sessionN
 }
 
 func GetService(ctx context.Context, dynCli dynamic.Interface, dmSession api.DatamoverSession) (*corev1.Service, error) {
+	return GetServiceWithClient(ctx, NewDynamicClient(dynCli), dmSession)
+}
+
+func GetServiceWithClient(ctx context.Context, cli Client, dmSession api.DatamoverSession) (*corev1.Service, error) {
 	// No service is not an error
 	if dmSession.Status.SessionInfo.ServiceName == "" {
 		return nil, nil
 	}
-	serviceName := dmSession.Status.SessionInfo.ServiceName
-	serviceNamespace := dmSession.Namespace
-	gvr := schema.GroupVersionResource{
-		Group:    "",
-		Version:  "v1",
-		Resource: "services",
-	}
-	serviceData, err := dynCli.Resource(gvr).Namespace(serviceNamespace).Get(ctx, serviceName, metav1.GetOptions{})
+	svc, err := cli.GetService(ctx, dmSession.Status.SessionInfo.ServiceName, dmSession.Namespace)
 	if err != nil {
 		return nil, errors.Wrap(err, "Unable to get service")
 	}
-
-	log.Log.Info("Service data object", "data", serviceData)
-	var svc corev1.Service
-	err = runtime.DefaultUnstructuredConverter.FromUnstructured(serviceData.Object, &svc)
-	if err != nil {
-		return nil, errors.Wrap(err, "Unable to parse service object")
-	}
-	return &svc, nil
+	log.Log.Info("Service data object", "data", svc)
+	return svc, nil
 }
 
 // TODO: do we need a nowait version of that?
 func GetConfig(ctx context.Context, dynCli dynamic.Interface, sessionName, sessionNamespace string) (*SessionConfig, error) {
-	session, err := WaitForReady(ctx, func() (*api.DatamoverSession, error) { return Get(ctx, dynCli, sessionName, sessionNamespace) })
+	return GetConfigWithClient(ctx, NewDynamicClient(dynCli), sessionName, sessionNamespace)
+}
+
+func GetConfigWithClient(ctx context.Context, cli Client, sessionName, sessionNamespace string) (*SessionConfig, error) {
+	session, err := WaitForReadyByNameWithClient(ctx, cli, sessionName, sessionNamespace)
 	if err != nil {
 		return nil, errors.Wrap(err, "Timeout waiting for session to be ready")
 	}
-	service, err := GetService(ctx, dynCli, *session)
+	service, err := GetServiceWithClient(ctx, cli, *session)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting service from session")
 	}
+	return MakeConfig(*session, service), nil
+}
+
+// MakeConfig creates session config from a ready session and its service without waiting
+func MakeConfig(dmSession api.DatamoverSession, service *corev1.Service) *SessionConfig {
 	return &SessionConfig{
-		Implementation: session.Spec.Implementation,
+		Implementation: dmSession.Spec.Implementation,
 		Service:        service,
-		SessionData:    session.Status.SessionInfo.SessionData,
-	}, nil
+		SessionData:    dmSession.Status.SessionInfo.SessionData,
+		SessionUID:     dmSession.UID,
+		ClusterDomain:  ClusterDomain(dmSession),
+		Endpoints:      dmSession.Status.SessionInfo.Endpoints,
+	}
 }
 
 func WaitForReadyByName(ctx context.Context, dynCli dynamic.Interface, sessionName, sessionNamespace string) (*api.DatamoverSession, error) {
-	return WaitForReady(ctx, func() (*api.DatamoverSession, error) { return Get(ctx, dynCli, sessionName, sessionNamespace) })
+	return WaitForReadyByNameWithClient(ctx, NewDynamicClient(dynCli), sessionName, sessionNamespace)
+}
+
+func WaitForReadyByNameWithClient(ctx context.Context, cli Client, sessionName, sessionNamespace string) (*api.DatamoverSession, error) {
+	return WaitForReady(ctx, func() (*api.DatamoverSession, error) { return cli.GetSession(ctx, sessionName, sessionNamespace) })
 }
 
 func WaitForReady(ctx context.Context, getFunc func() (*api.DatamoverSession, error)) (*api.DatamoverSession, error) {
@@ -130,6 +165,11 @@ func WaitForReadyWithTimeout(ctx context.Context, getFunc func() (*api.Datamover
 	}
 }
 
+// IsTerminated returns true if the session failed and will not become ready
+func IsTerminated(dmSession api.DatamoverSession) bool {
+	return isSessionTerminated(&dmSession)
+}
+
 func isSessionReady(dmSession *api.DatamoverSession) bool {
 	return dmSession != nil && dmSession.Status.Progress == api.ProgressReady
 }
@@ -145,50 +185,13 @@ func isSessionTerminated(dmSession *api.DatamoverSession) bool {
 }
 
 func Get(ctx context.Context, dynCli dynamic.Interface, sessionName, sessionNamespace string) (*api.DatamoverSession, error) {
-	client := dynCli.Resource(api.GroupVersion.WithResource(ResourceNamePlural)).Namespace(sessionNamespace)
-	us, err := client.Get(ctx, sessionName, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	dataMoverSession := api.DatamoverSession{}
-	unstructured := us.UnstructuredContent()
-	err = runtime.DefaultUnstructuredConverter.
-		FromUnstructured(unstructured, &dataMoverSession)
-	if err != nil {
-		return nil, err
-	}
-	return &dataMoverSession, nil
+	return NewDynamicClient(dynCli).GetSession(ctx, sessionName, sessionNamespace)
 }
 
 func Create(ctx context.Context, dynCli dynamic.Interface, dmSession api.DatamoverSession) (*api.DatamoverSession, error) {
-	dmSession.Kind = api.DatamoverSessionKind
-	dmSession.APIVersion = api.GroupVersion.String()
-
-	client := dynCli.Resource(api.GroupVersion.WithResource(ResourceNamePlural)).Namespace(dmSession.Namespace)
-	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&dmSession)
-	if err != nil {
-		return nil, err
-	}
-	us := &unstructured.Unstructured{}
-	us.SetUnstructuredContent(data)
-
-	res, err := client.Create(ctx, us, metav1.CreateOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	dataMoverSession := api.DatamoverSession{}
-	content := res.UnstructuredContent()
-	err = runtime.DefaultUnstructuredConverter.
-		FromUnstructured(content, &dataMoverSession)
-	if err != nil {
-		return nil, err
-	}
-	return &dataMoverSession, nil
+	return NewDynamicClient(dynCli).CreateSession(ctx, dmSession)
 }
 
 func Delete(ctx context.Context, dynCli dynamic.Interface, dmSession api.DatamoverSession) error {
-	client := dynCli.Resource(api.GroupVersion.WithResource(ResourceNamePlural)).Namespace(dmSession.Namespace)
-	return client.Delete(ctx, dmSession.Name, metav1.DeleteOptions{})
+	return NewDynamicClient(dynCli).DeleteSession(ctx, dmSession)
 }
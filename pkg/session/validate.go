@@ -29,6 +29,10 @@ func ValidateSession(dmSession api.DatamoverSession) error {
 		if err != nil {
 			return err
 		}
+		err = validateServiceConfig(dmSession)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -52,10 +56,30 @@ func validateEnvs(dmSession api.DatamoverSession) error {
 }
 
 func validateNetworkPolicyConfig(dmSession api.DatamoverSession) error {
-	if dmSession.Spec.LifecycleConfig.NetworkPolicy.Enabled {
+	config := dmSession.Spec.LifecycleConfig.NetworkPolicy
+	if config.Enabled {
 		if len(dmSession.Spec.LifecycleConfig.ServicePorts) == 0 {
 			return errors.New("ServicePorts should be set to create a network policy")
 		}
+	} else if config.AllowClients || config.Egress != nil {
+		return errors.New("NetworkPolicy should be enabled to use allowClients or egress")
+	}
+	if config.ClientNamespaceSelector != nil && !config.AllowClients {
+		return errors.New("NetworkPolicy clientNamespaceSelector requires allowClients")
+	}
+	return nil
+}
+
+func validateServiceConfig(dmSession api.DatamoverSession) error {
+	config := dmSession.Spec.LifecycleConfig.Service
+	if config == nil {
+		return nil
+	}
+	if len(dmSession.Spec.LifecycleConfig.ServicePorts) == 0 {
+		return errors.New("ServicePorts should be set to configure service")
+	}
+	if len(config.IPFamilies) > 2 {
+		return errors.New("Service can have at most two ipFamilies")
 	}
 	return nil
 }
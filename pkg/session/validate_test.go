@@ -2,6 +2,7 @@ package session
 
 import (
 	api "github.com/kanisterio/datamover/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"testing"
 )
 
@@ -127,3 +128,61 @@ func TestValidateFailLifecycleInValidEnv(t *testing.T) {
 		t.Errorf("Validation with %v env passed, but should have failed", api.ProtocolsEnvVarName)
 	}
 }
+
+func TestValidateFailNetworkPolicyOptionsWhenDisabled(t *testing.T) {
+	session := api.DatamoverSession{
+		Spec: api.DatamoverSessionSpec{
+			Implementation: "foo",
+			LifecycleConfig: &api.LifecycleConfig{
+				Image: "image",
+				NetworkPolicy: api.NetworkPolicyConfig{
+					AllowClients: true,
+				},
+			},
+		},
+	}
+	err := ValidateSession(session)
+	if err == nil {
+		t.Errorf("Validation with allowClients and disabled network policy passed, but should have failed")
+	}
+}
+
+func TestValidatePassNetworkPolicyAllowClients(t *testing.T) {
+	session := api.DatamoverSession{
+		Spec: api.DatamoverSessionSpec{
+			Implementation: "foo",
+			LifecycleConfig: &api.LifecycleConfig{
+				Image: "image",
+				ServicePorts: []corev1.ServicePort{
+					{Name: "foo", Port: 2000},
+				},
+				NetworkPolicy: api.NetworkPolicyConfig{
+					Enabled:      true,
+					AllowClients: true,
+				},
+			},
+		},
+	}
+	err := ValidateSession(session)
+	if err != nil {
+		t.Errorf("Validation failed %v", err)
+	}
+}
+
+func TestValidateFailServiceConfigNoPorts(t *testing.T) {
+	session := api.DatamoverSession{
+		Spec: api.DatamoverSessionSpec{
+			Implementation: "foo",
+			LifecycleConfig: &api.LifecycleConfig{
+				Image: "image",
+				Service: &api.ServiceConfig{
+					Type: api.ServiceTypeLoadBalancer,
+				},
+			},
+		},
+	}
+	err := ValidateSession(session)
+	if err == nil {
+		t.Errorf("Validation with service config and no ports passed, but should have failed")
+	}
+}